@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	ui "github.com/awesome-gocui/gocui"
+)
+
+// Open a prompt to search the user to assign the current issue to
+func AssignIssuePrompt(g *ui.Gui, v *ui.View) error {
+	issueKey := issueKeyFromRow(IssuesList.CurrentItem())
+	if issueKey == "" {
+		return nil
+	}
+
+	IssuesList.Unfocus()
+
+	createPromptView(g, CreateDialogOptions{
+		title: fmt.Sprintf("%s| %s ", AssignTitle, issueKey),
+		value: issueKey,
+		suggest: func(input string) []Suggestion {
+			return suggestAssignableUsers(issueKey, input)
+		},
+	})
+
+	return nil
+}
+
+func AssignToMe(g *ui.Gui, v *ui.View) error {
+	issueKey := issueKeyFromRow(IssuesList.CurrentItem())
+	if issueKey == "" {
+		return nil
+	}
+
+	IssuesList.SetTitle(fmt.Sprintf(" Issues | Assigning %s... ", issueKey))
+
+	g.Update(func(g *ui.Gui) error {
+		me, err := GetCurrentUser()
+		if err != nil {
+			return showAssignError(g, err)
+		}
+
+		if err := AssignIssue(issueKey, me.AccountID); err != nil {
+			return showAssignError(g, err)
+		}
+
+		return refreshAfterAssign(g, issueKey)
+	})
+
+	return nil
+}
+
+func UnassignIssue(g *ui.Gui, v *ui.View) error {
+	issueKey := issueKeyFromRow(IssuesList.CurrentItem())
+	if issueKey == "" {
+		return nil
+	}
+
+	IssuesList.SetTitle(fmt.Sprintf(" Issues | Unassigning %s... ", issueKey))
+
+	g.Update(func(g *ui.Gui) error {
+		if err := AssignIssue(issueKey, ""); err != nil {
+			return showAssignError(g, err)
+		}
+
+		return refreshAfterAssign(g, issueKey)
+	})
+
+	return nil
+}
+
+// refreshAfterAssign reloads what shows the assignee: the "me" list and the
+// Details view when it displays the same issue
+func refreshAfterAssign(g *ui.Gui, issueKey string) error {
+	if strings.EqualFold(IssuesList.code, AssignedToMeKey) {
+		if err := FetchIssues(g, IssuesList.code); err != nil {
+			IssuesList.SetTitle(" Issues (Error!) ")
+			return nil
+		}
+	}
+
+	IssuesList.SetTitle(makeIssuesTitle())
+
+	if CurrentIssue != nil && CurrentIssue.Key == issueKey {
+		return OpenIssue(g, issueKey, false)
+	}
+
+	return nil
+}
+
+func showAssignError(g *ui.Gui, err error) error {
+	IssuesList.SetTitle(makeIssuesTitle())
+	IssuesList.Unfocus()
+
+	createAlertView(g, CreateDialogOptions{
+		title:   AssignErrorTitle,
+		content: err.Error(),
+	})
+
+	return nil
+}
+
+func suggestAssignableUsers(issueKey string, input string) []Suggestion {
+	users, err := SearchAssignableUsers(issueKey, input)
+	if err != nil {
+		return []Suggestion{}
+	}
+
+	found := make([]Suggestion, len(users))
+	for index, user := range users {
+		label := user.DisplayName
+		if user.EmailAddress != "" {
+			label = fmt.Sprintf("%s <%s>", user.DisplayName, user.EmailAddress)
+		}
+		found[index] = Suggestion{Label: label, Value: user.DisplayName, ID: user.AccountID}
+	}
+
+	return found
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	// Image formats which dimensions can be read
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+	config "github.com/gookit/config/v2"
+)
+
+const (
+	// Enough bytes to read the header of the common image formats
+	ImageHeadLength = 64 * 1024
+
+	// How often the progress of a download is refreshed
+	DownloadProgressInterval = 200 * time.Millisecond
+
+	DefaultDownloadDir = "~/Downloads"
+)
+
+// The dimensions of the images, by attachment id. An empty string means the
+// dimensions are being read or could not be read
+var imageSizes = make(map[string]string)
+
+// makeAttachmentLines is used by the Attachments tab of the Details view, the
+// dimensions of the images are shown once they are read in background
+func makeAttachmentLines(g *ui.Gui, issue *jira.Issue) *DetailsLines {
+	lines := &DetailsLines{}
+
+	attachments := issue.Fields.Attachments
+	if len(attachments) == 0 {
+		lines.Add(DetailsRef{}, "No attachments")
+		return lines
+	}
+
+	total := 0
+	for _, attachment := range attachments {
+		total += attachment.Size
+	}
+	lines.Add(DetailsRef{}, fmt.Sprintf("%d file(s), %s", len(attachments), formatSize(total)), "")
+
+	width := DetailsList.width()
+	unknown := make([]*jira.Attachment, 0)
+	for _, attachment := range attachments {
+		ref := DetailsRef{AttachmentRef, attachment.ID}
+
+		lines.Add(ref, truncate(attachment.Filename, width))
+
+		created := attachment.Created
+		if date := parseJiraTime(attachment.Created); !date.IsZero() {
+			created = date.Local().Format(DateTimeLayout)
+		}
+
+		details := []string{formatSize(attachment.Size), getUserName(attachment.Author), created}
+		if strings.HasPrefix(attachment.MimeType, "image/") {
+			size, ok := imageSizes[attachment.ID]
+			if !ok {
+				unknown = append(unknown, attachment)
+			}
+			if size != "" {
+				details = append(details, size)
+			}
+		}
+		lines.Add(ref, fmt.Sprintf("    %s", strings.Join(details, " · ")))
+	}
+
+	if len(unknown) > 0 {
+		loadImageSizes(g, issue.Key, unknown)
+	}
+
+	return lines
+}
+
+// loadImageSizes reads the dimensions of the images in background, the tab is
+// drawn again if it still shows the issue
+func loadImageSizes(g *ui.Gui, issueKey string, attachments []*jira.Attachment) {
+	for _, attachment := range attachments {
+		imageSizes[attachment.ID] = ""
+	}
+
+	go func() {
+		sizes := make(map[string]string, len(attachments))
+		for _, attachment := range attachments {
+			sizes[attachment.ID] = readImageSize(attachment)
+		}
+
+		g.Update(func(g *ui.Gui) error {
+			for id, size := range sizes {
+				imageSizes[id] = size
+			}
+
+			if CurrentIssue != nil && CurrentIssue.Key == issueKey && CurrentTab == AttachmentsTab {
+				renderDetails(g)
+			}
+
+			return nil
+		})
+	}()
+}
+
+// readImageSize reads the dimensions of an image from its first bytes, it
+// must run outside of the main loop
+func readImageSize(attachment *jira.Attachment) string {
+	head, err := GetAttachmentHead(attachment.Content, ImageHeadLength)
+	if err != nil {
+		return ""
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(head))
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%d×%d", imageConfig.Width, imageConfig.Height)
+}
+
+// Download the attachment on the current line in background, the progress
+// is displayed in the status bar
+func DownloadCurrentAttachment(g *ui.Gui, v *ui.View) error {
+	attachment := findCurrentAttachment()
+	if attachment == nil {
+		return nil
+	}
+
+	dir := expandHome(config.String(DownloadDirKey, DefaultDownloadDir))
+	open := config.Bool(OpenDownloadsKey)
+
+	ShowStatus(g, fmt.Sprintf("Downloading %s...", attachment.Filename))
+
+	go func() {
+		path, err := downloadAttachment(g, attachment, dir)
+
+		g.Update(func(g *ui.Gui) error {
+			if err != nil {
+				ShowStatus(g, fmt.Sprintf("Failed to download %s: %s", attachment.Filename, err))
+				return nil
+			}
+
+			ShowStatus(g, fmt.Sprintf("Downloaded %s", path))
+
+			if open {
+				if err := openExternally(path); err != nil {
+					ShowStatus(g, fmt.Sprintf("Failed to open %s: %s", path, err))
+				}
+			}
+
+			return nil
+		})
+	}()
+
+	return nil
+}
+
+// downloadAttachment writes the attachment into the directory, it must run
+// outside of the main loop
+func downloadAttachment(g *ui.Gui, attachment *jira.Attachment, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	resp, err := DownloadAttachment(attachment.ID)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	path := makeDownloadPath(dir, attachment.Filename)
+	file, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	progress := &progressWriter{
+		total: attachment.Size,
+		report: func(done int, total int) {
+			g.Update(func(g *ui.Gui) error {
+				ShowStatus(g, fmt.Sprintf("Downloading %s... %d%%", attachment.Filename, done*100/total))
+				return nil
+			})
+		},
+	}
+
+	if _, err := io.Copy(file, io.TeeReader(resp.Body, progress)); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", err
+	}
+
+	return path, file.Close()
+}
+
+// progressWriter counts the bytes going through it and reports the progress
+// at most every DownloadProgressInterval
+type progressWriter struct {
+	done     int
+	total    int
+	reported time.Time
+	report   func(done int, total int)
+}
+
+func (p *progressWriter) Write(data []byte) (int, error) {
+	p.done += len(data)
+
+	if p.total > 0 && time.Since(p.reported) >= DownloadProgressInterval {
+		p.reported = time.Now()
+		p.report(p.done, p.total)
+	}
+
+	return len(data), nil
+}
+
+// makeDownloadPath never overwrites an existing file, "file.png" becomes
+// "file (1).png" when it is already there
+func makeDownloadPath(dir string, filename string) string {
+	filename = filepath.Base(filename)
+	path := filepath.Join(dir, filename)
+
+	extension := filepath.Ext(filename)
+	name := strings.TrimSuffix(filename, extension)
+
+	for index := 1; ; index++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", name, index, extension))
+	}
+}
+
+// Ask for a file to attach to the current issue
+func AttachFilePrompt(g *ui.Gui, v *ui.View) error {
+	if CurrentIssue == nil {
+		return nil
+	}
+
+	DetailsList.Unfocus()
+
+	createPromptView(g, CreateDialogOptions{
+		title:   fmt.Sprintf("%s| %s ", AttachFileTitle, CurrentIssue.Key),
+		value:   CurrentIssue.Key,
+		suggest: suggestFilePaths,
+	})
+
+	return nil
+}
+
+// submitAttachment uploads the file in background, the issue is reloaded
+// once it is attached
+func submitAttachment(g *ui.Gui, input string) error {
+	path := expandHome(input)
+
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		PromptDialog.Subtitle = " Not a file "
+		return nil
+	}
+
+	issueKey := PromptDialog.value
+
+	deletePromptView(g)
+	DetailsList.Focus(g)
+	ShowStatus(g, fmt.Sprintf("Uploading %s...", filepath.Base(path)))
+
+	go func() {
+		err := UploadAttachment(issueKey, path)
+
+		g.Update(func(g *ui.Gui) error {
+			if err != nil {
+				ShowStatus(g, fmt.Sprintf("Failed to upload %s: %s", filepath.Base(path), err))
+				return nil
+			}
+
+			ShowStatus(g, fmt.Sprintf("Attached %s to %s", filepath.Base(path), issueKey))
+
+			if CurrentIssue != nil && CurrentIssue.Key == issueKey {
+				return OpenIssue(g, issueKey, false)
+			}
+
+			return nil
+		})
+	}()
+
+	return nil
+}
+
+// suggestFilePaths completes the path typed in the prompt with the entries
+// of its directory, directories end with a slash so Tab goes into them
+func suggestFilePaths(input string) []Suggestion {
+	dir, prefix := filepath.Split(input)
+	if dir == "" {
+		dir = "./"
+	}
+
+	entries, err := os.ReadDir(expandHome(dir))
+	if err != nil {
+		return nil
+	}
+
+	found := make([]Suggestion, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+
+		value := name
+		if strings.Contains(input, "/") {
+			value = dir + name
+		}
+		found = append(found, Suggestion{Label: name, Value: value})
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Label < found[j].Label
+	})
+
+	return found
+}
+
+func findCurrentAttachment() *jira.Attachment {
+	ref := currentDetailsRef()
+	if CurrentIssue == nil || ref.Kind != AttachmentRef {
+		return nil
+	}
+
+	for _, attachment := range CurrentIssue.Fields.Attachments {
+		if attachment.ID == ref.ID {
+			return attachment
+		}
+	}
+
+	return nil
+}
+
+// formatSize writes the size in bytes with the closest unit, e.g. 1.2 MB
+func formatSize(size int) string {
+	units := []string{"B", "KB", "MB", "GB"}
+
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d %s", size, units[unit])
+	}
+
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	ui "github.com/awesome-gocui/gocui"
+)
+
+// Suggestion is one entry proposed below the prompt, the label is displayed
+// and the value is inserted into the prompt when accepted with Tab. The ID
+// is kept for entries which can not be typed, like the account id of a user
+type Suggestion struct {
+	Label string
+	Value string
+	ID    string
+}
+
+// SuggestFunc computes the suggestions for what is typed in the prompt, it is
+// run outside of the main loop so it can call the Jira API. It must not read
+// the state of the UI (CurrentIssues, History...), the candidates taken from
+// it are collected before the prompt opens
+type SuggestFunc func(input string) []Suggestion
+
+var (
+	SuggestionsList *List
+
+	suggestions     []Suggestion
+	suggestionsSeed int
+
+	// Whether the highlighted suggestion was chosen with the arrows, it is
+	// forgotten when the suggestions are refreshed
+	suggestionPicked bool
+
+	// The suggest function of the open prompt
+	activeSuggest SuggestFunc
+)
+
+// Creates the suggestions list below the prompt and attaches the editor
+// which refreshes it after each key stroke
+func createSuggestionsView(g *ui.Gui, suggest SuggestFunc) {
+	tw, th := g.Size()
+	v, err := g.SetView(SuggestionsView, tw/6, (th/2)-5, (tw*5)/6, (th/2)+3, 0)
+	if err != nil && err != ui.ErrUnknownView {
+		log.Panicln("Error while creating suggestions view", err)
+	}
+
+	SuggestionsList = CreateList(v, false)
+	SuggestionsList.Highlight = true
+	SuggestionsList.SelFgColor = ui.ColorBlack
+	SuggestionsList.SelBgColor = ui.ColorBlue
+	SuggestionsList.SetTitle(SuggestionsTitle)
+
+	activeSuggest = suggest
+
+	PromptDialog.Editor = ui.EditorFunc(func(v *ui.View, key ui.Key, ch rune, mod ui.Modifier) {
+		ui.DefaultEditor.Edit(v, key, ch, mod)
+		refreshSuggestions(g, suggest, strings.TrimSpace(v.ViewBuffer()))
+	})
+
+	refreshSuggestions(g, suggest, strings.TrimSpace(PromptDialog.ViewBuffer()))
+}
+
+func deleteSuggestionsView(g *ui.Gui) {
+	if _, err := g.View(SuggestionsView); err != nil {
+		return
+	}
+
+	suggestions = nil
+	suggestionsSeed++
+	suggestionPicked = false
+
+	if err := g.DeleteView(SuggestionsView); err != nil {
+		log.Panicln("Error while deleting suggestions view", err)
+	}
+}
+
+// refreshSuggestions runs the suggest function in background, results of an
+// older input are dropped when they arrive after the newer ones
+func refreshSuggestions(g *ui.Gui, suggest SuggestFunc, input string) {
+	suggestionsSeed++
+	seed := suggestionsSeed
+
+	go func() {
+		found := suggest(input)
+
+		g.Update(func(g *ui.Gui) error {
+			if seed != suggestionsSeed {
+				return nil
+			}
+			if _, err := g.View(SuggestionsView); err != nil {
+				return nil
+			}
+
+			suggestions = found
+			suggestionPicked = false
+
+			labels := make([]string, len(found))
+			for index, suggestion := range found {
+				labels[index] = suggestion.Label
+			}
+
+			SuggestionsList.Reset()
+			SuggestionsList.SetItems(labels)
+			SuggestionsList.SetTitle(fmt.Sprintf("%s(%d) ", SuggestionsTitle, len(found)))
+
+			return nil
+		})
+	}()
+}
+
+// Fill the prompt with the selected suggestion
+func AcceptSuggestion(g *ui.Gui, v *ui.View) error {
+	if _, err := g.View(SuggestionsView); err != nil {
+		return nil
+	}
+
+	index := SuggestionsList.CurrentIndex()
+	if index < 0 || index >= len(suggestions) {
+		return nil
+	}
+
+	value := suggestions[index].Value
+
+	v.Clear()
+	if _, err := fmt.Fprint(v, value); err != nil {
+		return err
+	}
+
+	// e.g. a directory was accepted, propose what it contains
+	refreshSuggestions(g, activeSuggest, value)
+
+	return v.SetCursor(len(value), 0)
+}
+
+// findSuggestion returns the suggestion matching what was typed, or the
+// highlighted one when it was chosen with the arrows. The highlighted row
+// alone may come from the suggestions of an older input
+func findSuggestion(g *ui.Gui, input string) *Suggestion {
+	if _, err := g.View(SuggestionsView); err != nil {
+		return nil
+	}
+
+	for index := range suggestions {
+		if strings.EqualFold(suggestions[index].Value, input) {
+			return &suggestions[index]
+		}
+	}
+
+	if !suggestionPicked {
+		return nil
+	}
+
+	index := SuggestionsList.CurrentIndex()
+	if index < 0 || index >= len(suggestions) {
+		return nil
+	}
+
+	return &suggestions[index]
+}
+
+func NextSuggestion(g *ui.Gui, v *ui.View) error {
+	if _, err := g.View(SuggestionsView); err != nil {
+		return nil
+	}
+
+	suggestionPicked = true
+
+	return SuggestionsList.MoveDown()
+}
+
+func PrevSuggestion(g *ui.Gui, v *ui.View) error {
+	if _, err := g.View(SuggestionsView); err != nil {
+		return nil
+	}
+
+	suggestionPicked = true
+
+	return SuggestionsList.MoveUp()
+}
+
+// filterSuggestions keeps the suggestions containing every word of the input
+func filterSuggestions(candidates []Suggestion, input string) []Suggestion {
+	words := strings.Fields(strings.ToLower(input))
+
+	found := make([]Suggestion, 0)
+	for _, candidate := range candidates {
+		label := strings.ToLower(candidate.Label)
+
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(label, word) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			found = append(found, candidate)
+		}
+	}
+
+	return found
+}
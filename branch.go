@@ -0,0 +1,600 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+	git "github.com/go-git/go-git/v5"
+	plumbing "github.com/go-git/go-git/v5/plumbing"
+	config "github.com/gookit/config/v2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Used when neither git.branchTemplate nor the project define a template, the
+// legacy "prefix" setting is still honored
+const DefaultBranchTemplate = `{{if .Prefix}}{{.Prefix}}/{{end}}{{.Key}}-{{.Summary | slug 50}}`
+
+// BranchTemplateData is what the branch templates can use, e.g.
+// {{.Type | lower}}/{{.Key}}-{{.Summary | slug 40}}
+type BranchTemplateData struct {
+	Key      string
+	Summary  string
+	Type     string
+	Status   string
+	Priority string
+	Project  string
+	Assignee string
+	Prefix   string
+}
+
+var branchTemplateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"slug":  Slugify,
+}
+
+// Letters which are not a base letter plus accents, so they are not handled
+// by the decomposition
+var transliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'ø': "o", 'Ø': "O", 'œ': "oe", 'Œ': "OE",
+	'đ': "d", 'Đ': "D", 'ł': "l", 'Ł': "L", 'þ': "th", 'Þ': "TH", 'ð': "d", 'Ð': "D",
+}
+
+var (
+	slugSeparatorRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+	// Characters forbidden in a ref name by git check-ref-format
+	refForbiddenRegexp = regexp.MustCompile(`[\x00-\x20\x7f~^:?*\[\\]+`)
+)
+
+// createBranchPrompt asks for the name of the branch to create, the name
+// made from the branch template is suggested
+func createBranchPrompt(g *ui.Gui, issue *jira.Issue) error {
+	branchName, err := makeBranchName(issue)
+	if err != nil {
+		ShowStatus(g, err.Error())
+		return nil
+	}
+
+	IssuesList.Unfocus()
+
+	createPromptView(g, CreateDialogOptions{
+		title:   fmt.Sprintf("%s| %s ", NewBranchTitle, issue.Key),
+		content: branchName,
+		value:   issue.Key,
+	})
+
+	if err := PromptDialog.SetCursor(len(branchName), 0); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// makeBranchName renders the branch template of the issue project, or the
+// global one, with the issue
+func makeBranchName(issue *jira.Issue) (string, error) {
+	data := makeBranchTemplateData(issue)
+
+	tmpl, err := template.New("branch").Funcs(branchTemplateFuncs).Parse(getBranchTemplate(data.Project))
+	if err != nil {
+		return "", fmt.Errorf("invalid branch template: %w", err)
+	}
+
+	var name bytes.Buffer
+	if err := tmpl.Execute(&name, data); err != nil {
+		return "", fmt.Errorf("invalid branch template: %w", err)
+	}
+
+	return SanitizeBranchName(name.String()), nil
+}
+
+// getBranchTemplate prefers the template of the project, e.g.
+// projects.abc.branchTemplate, over git.branchTemplate
+func getBranchTemplate(projectCode string) string {
+	if projectCode != "" {
+		path := fmt.Sprintf("%s.%s.branchTemplate", ProjectsKey, strings.ToLower(projectCode))
+		if value := config.String(path); value != "" {
+			return value
+		}
+	}
+
+	return config.String(BranchTemplateKey, DefaultBranchTemplate)
+}
+
+func makeBranchTemplateData(issue *jira.Issue) BranchTemplateData {
+	data := BranchTemplateData{
+		Key:    issue.Key,
+		Prefix: config.String(GitPrefixKey),
+	}
+
+	fields := issue.Fields
+	if fields == nil {
+		return data
+	}
+
+	data.Summary = fields.Summary
+	data.Type = fields.Type.Name
+	data.Project = fields.Project.Key
+	data.Priority = getPriorityName(fields.Priority)
+	if fields.Status != nil {
+		data.Status = fields.Status.Name
+	}
+	if fields.Assignee != nil {
+		data.Assignee = fields.Assignee.DisplayName
+	}
+	if data.Project == "" {
+		data.Project, _, _ = strings.Cut(issue.Key, "-")
+	}
+
+	return data
+}
+
+// Slugify keeps the lowercase ASCII letters and digits of the text separated
+// by dashes, at most max characters long. Accents are removed, e.g.
+// "Crème brûlée: über" becomes "creme-brulee-uber"
+func Slugify(max int, text string) string {
+	slug := slugSeparatorRegexp.ReplaceAllString(strings.ToLower(transliterate(text)), "-")
+	slug = strings.Trim(slug, "-")
+
+	if max > 0 && len(slug) > max {
+		// Prefer cutting between two words when it keeps most of the text
+		cut := slug[:max+1]
+		if index := strings.LastIndex(cut, "-"); index >= max/2 {
+			slug = slug[:index]
+		} else {
+			slug = strings.TrimRight(slug[:max], "-")
+		}
+	}
+
+	return slug
+}
+
+// transliterate writes the text with ASCII letters when possible
+func transliterate(text string) string {
+	var result strings.Builder
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if replacement, ok := transliterations[r]; ok {
+			result.WriteString(replacement)
+			continue
+		}
+		result.WriteRune(r)
+	}
+
+	return result.String()
+}
+
+// SanitizeBranchName removes what git check-ref-format does not allow in a
+// branch name, the slashes separating the components are kept
+func SanitizeBranchName(name string) string {
+	name = refForbiddenRegexp.ReplaceAllString(strings.TrimSpace(name), "-")
+	name = strings.ReplaceAll(name, "@{", "-")
+	for strings.Contains(name, "..") {
+		name = strings.ReplaceAll(name, "..", ".")
+	}
+
+	components := make([]string, 0)
+	for _, component := range strings.Split(name, "/") {
+		component = strings.TrimLeft(component, ".-")
+		for strings.HasSuffix(component, ".lock") {
+			component = strings.TrimSuffix(component, ".lock")
+		}
+		component = strings.TrimRight(component, ".-")
+		if component != "" {
+			components = append(components, component)
+		}
+	}
+
+	name = strings.Join(components, "/")
+	if name == "@" {
+		return ""
+	}
+
+	return name
+}
+
+// BranchRequest is the branch being created, it is completed by each dialog:
+// name, base, then what to do with the uncommitted changes
+type BranchRequest struct {
+	Name     string
+	IssueKey string
+	RepoPath string
+
+	// Resolved once fetched, e.g. "refs/remotes/origin/main" or "HEAD"
+	Base string
+
+	Stash bool
+	Carry bool
+}
+
+// Choices of the base picker, any other ref is typed in a prompt
+const (
+	DefaultBaseChoice = iota
+	HeadBaseChoice
+	OtherBaseChoice
+)
+
+// Choices of the Uncommitted changes picker
+const (
+	StashChoice = iota
+	CarryChoice
+)
+
+var newBranch *BranchRequest
+
+// submitBranchName checks the name typed in the New branch prompt, then asks
+// which branch to start from
+func submitBranchName(g *ui.Gui, input string) error {
+	name := SanitizeBranchName(input)
+	if name == "" {
+		PromptDialog.Subtitle = " Invalid branch name "
+		return nil
+	}
+
+	// The repository is kept, it could be switched while the dialogs are open
+	path := gitRepoPath
+	repo, err := openRepositoryAt(path)
+	if err != nil {
+		PromptDialog.Subtitle = " Not in a git repository "
+		return nil
+	}
+
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName(name), false); err == nil {
+		PromptDialog.Subtitle = fmt.Sprintf(" The branch %s already exists ", name)
+		return nil
+	}
+
+	newBranch = &BranchRequest{Name: name, IssueKey: PromptDialog.value, RepoPath: path}
+
+	deletePromptView(g)
+
+	head := "HEAD"
+	if branch, err := getCurrentBranch(repo); err == nil {
+		head = branch
+	}
+
+	items := []string{
+		fmt.Sprintf("Default branch (%s)", getDefaultBranch(repo).Short()),
+		fmt.Sprintf("Current HEAD (%s)", head),
+		"Other ref...",
+	}
+	createPickerView(g, CreateDialogOptions{title: fmt.Sprintf("%s| %s ", BaseBranchTitle, name)}, items)
+
+	return nil
+}
+
+// chooseBranchBase is called with the index of the item chosen in the base
+// picker
+func chooseBranchBase(g *ui.Gui, index int) error {
+	repo, err := openRepositoryAt(newBranch.RepoPath)
+	if err != nil {
+		IssuesList.Focus(g)
+		ShowStatus(g, fmt.Sprintf("Failed to open the repository: %s", err))
+		return nil
+	}
+
+	switch index {
+	case DefaultBaseChoice:
+		newBranch.Base = getDefaultBranch(repo).String()
+	case HeadBaseChoice:
+		newBranch.Base = plumbing.HEAD.String()
+	default:
+		createPromptView(g, CreateDialogOptions{
+			title:   fmt.Sprintf("%s| %s ", BaseRefTitle, newBranch.Name),
+			suggest: suggestRefs,
+		})
+		return nil
+	}
+
+	return checkUncommittedChanges(g)
+}
+
+// submitBaseRef checks the ref typed in the Base ref prompt, it can be a
+// branch, a tag or a commit
+func submitBaseRef(g *ui.Gui, input string) error {
+	repo, err := openRepositoryAt(newBranch.RepoPath)
+	if err != nil {
+		PromptDialog.Subtitle = " Not in a git repository "
+		return nil
+	}
+
+	if _, err := repo.ResolveRevision(plumbing.Revision(input)); err != nil {
+		PromptDialog.Subtitle = fmt.Sprintf(" Unknown ref %s ", input)
+		return nil
+	}
+
+	newBranch.Base = input
+
+	deletePromptView(g)
+
+	return checkUncommittedChanges(g)
+}
+
+// checkUncommittedChanges asks what to do with the changes of the worktree
+// before creating the branch, if there are any
+func checkUncommittedChanges(g *ui.Gui) error {
+	repo, err := openRepositoryAt(newBranch.RepoPath)
+	if err != nil {
+		IssuesList.Focus(g)
+		ShowStatus(g, fmt.Sprintf("Failed to open the repository: %s", err))
+		return nil
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		IssuesList.Focus(g)
+		ShowStatus(g, fmt.Sprintf("Failed to read the worktree: %s", err))
+		return nil
+	}
+
+	dirty, err := isWorktreeDirty(w)
+	if err != nil {
+		IssuesList.Focus(g)
+		ShowStatus(g, fmt.Sprintf("Failed to read the worktree: %s", err))
+		return nil
+	}
+
+	if !dirty {
+		return startBranchCreation(g)
+	}
+
+	items := []string{"Stash them", "Carry them over to the new branch"}
+	createPickerView(g, CreateDialogOptions{title: fmt.Sprintf("%s| %s ", DirtyTreeTitle, newBranch.Name)}, items)
+
+	return nil
+}
+
+// chooseUncommittedChanges is called with the index of the item chosen in the
+// Uncommitted changes picker
+func chooseUncommittedChanges(g *ui.Gui, index int) error {
+	newBranch.Stash = index == StashChoice
+	newBranch.Carry = index == CarryChoice
+
+	return startBranchCreation(g)
+}
+
+// startBranchCreation creates the branch in background, fetching and pushing
+// can take a while
+func startBranchCreation(g *ui.Gui) error {
+	request := *newBranch
+	newBranch = nil
+
+	IssuesList.Focus(g)
+	ShowStatus(g, fmt.Sprintf("Creating %s...", request.Name))
+
+	go func() {
+		err := createBranch(&request, func(message string) {
+			g.Update(func(g *ui.Gui) error {
+				ShowStatus(g, message)
+				return nil
+			})
+		})
+
+		// The issue is only updated once the branch is checked out
+		outcome := ""
+		if err == nil {
+			outcome = applyBranchCreateRule(request.IssueKey)
+		}
+
+		g.Update(func(g *ui.Gui) error {
+			if err != nil {
+				ShowStatus(g, fmt.Sprintf("Failed to create %s: %s", request.Name, err))
+				return nil
+			}
+
+			message := fmt.Sprintf("Switched to the new branch %s", request.Name)
+			if request.Stash {
+				message += ", your changes are stashed"
+			}
+			if outcome != "" {
+				message += ". " + outcome
+			}
+			ShowStatus(g, message)
+
+			refreshLocalBranchKeys()
+
+			forgetTabsData(request.IssueKey)
+			if CurrentIssue != nil && CurrentIssue.Key == request.IssueKey && CurrentTab == GitTab {
+				renderDetails(g)
+			}
+
+			if outcome != "" {
+				return refreshAfterBranchRule(g, request.IssueKey)
+			}
+
+			return redrawIssueRows()
+		})
+	}()
+
+	return nil
+}
+
+// BranchCreateRule is what is done to the issue once its branch is created,
+// from projects.<code>.onBranchCreate or git.onBranchCreate
+type BranchCreateRule struct {
+	Transition string
+	AssignToMe bool
+}
+
+func getBranchCreateRule(projectCode string) BranchCreateRule {
+	path := fmt.Sprintf("%s.%s.onBranchCreate", ProjectsKey, strings.ToLower(projectCode))
+	if !config.Exists(path) {
+		path = OnBranchCreateKey
+	}
+
+	return BranchCreateRule{
+		Transition: config.String(path + ".transition"),
+		AssignToMe: config.Bool(path + ".assignToMe"),
+	}
+}
+
+// applyBranchCreateRule assigns the issue before moving it, since some
+// workflows only let the assignee start the work. It returns the outcome,
+// empty when there is no rule
+func applyBranchCreateRule(issueKey string) string {
+	projectCode, _, _ := strings.Cut(issueKey, "-")
+	rule := getBranchCreateRule(projectCode)
+
+	outcomes := make([]string, 0, 2)
+
+	if rule.AssignToMe {
+		me, err := GetCurrentUser()
+		if err == nil {
+			err = AssignIssue(issueKey, me.AccountID)
+		}
+
+		if err != nil {
+			outcomes = append(outcomes, fmt.Sprintf("failed to assign %s: %s", issueKey, err))
+		} else {
+			outcomes = append(outcomes, fmt.Sprintf("assigned %s to you", issueKey))
+		}
+	}
+
+	if rule.Transition != "" {
+		outcomes = append(outcomes, applyBranchTransition(issueKey, rule.Transition))
+	}
+
+	if len(outcomes) == 0 {
+		return ""
+	}
+
+	outcome := strings.Join(outcomes, ", ")
+
+	return strings.ToUpper(outcome[:1]) + outcome[1:]
+}
+
+// applyBranchTransition moves the issue unless it is already in the status
+// the transition leads to, e.g. when a second branch is created for it
+func applyBranchTransition(issueKey string, name string) string {
+	issue, err := GetIssueByKey(issueKey)
+	if err != nil {
+		return fmt.Sprintf("failed to move %s: %s", issueKey, err)
+	}
+
+	status := ""
+	if issue.Fields != nil && issue.Fields.Status != nil {
+		status = issue.Fields.Status.Name
+	}
+	if strings.EqualFold(status, name) {
+		return fmt.Sprintf("%s is already in %s", issueKey, status)
+	}
+
+	transition, err := findIssueTransition(issueKey, name)
+	if err == nil && strings.EqualFold(transition.To.Name, status) {
+		return fmt.Sprintf("%s is already in %s", issueKey, status)
+	}
+	if err == nil {
+		err = TransitionIssue(issueKey, transition.ID)
+	}
+	if err != nil {
+		return fmt.Sprintf("failed to move %s: %s", issueKey, err)
+	}
+
+	return fmt.Sprintf("moved %s to %s", issueKey, transition.To.Name)
+}
+
+// refreshAfterBranchRule reloads the issues, the status or the assignee of
+// the issue changed
+func refreshAfterBranchRule(g *ui.Gui, issueKey string) error {
+	if IssuesList.code != "" {
+		if err := FetchIssues(g, IssuesList.code); err != nil {
+			IssuesList.SetTitle(" Issues (Error!) ")
+			return nil
+		}
+		IssuesList.SetTitle(makeIssuesTitle())
+
+		if index := findIssueRowIndex(issueKey); index >= 0 {
+			if err := IssuesList.SelectIndex(index); err != nil {
+				return err
+			}
+		}
+	}
+
+	if CurrentIssue != nil && CurrentIssue.Key == issueKey {
+		return OpenIssue(g, issueKey, false)
+	}
+
+	return nil
+}
+
+// createBranch fetches if git.fetchBeforeCreate is set, creates the branch
+// from its base and checks it out, then pushes it if git.pushAfterCreate is set
+func createBranch(request *BranchRequest, progress func(message string)) error {
+	repo, err := openRepositoryAt(request.RepoPath)
+	if err != nil {
+		return err
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	dir := w.Filesystem.Root()
+	remote := getGitRemote()
+
+	_, err = repo.Remote(remote)
+	hasRemote := err == nil
+
+	if hasRemote && config.Bool(FetchBeforeKey) {
+		progress(fmt.Sprintf("Fetching %s...", remote))
+		if err := runGit(dir, "fetch", remote); err != nil {
+			return fmt.Errorf("fetch failed: %w", err)
+		}
+	}
+
+	base, err := repo.ResolveRevision(plumbing.Revision(request.Base))
+	if err != nil {
+		return fmt.Errorf("unknown base %s: %w", request.Base, err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	branch := plumbing.NewBranchReferenceName(request.Name)
+	if _, err := repo.Reference(branch, false); err == nil {
+		return fmt.Errorf("the branch already exists")
+	}
+
+	if request.Stash {
+		progress("Stashing your changes...")
+		if err := runGit(dir, "stash", "push", "-m", fmt.Sprintf("lazyjira: before creating %s", request.Name)); err != nil {
+			return fmt.Errorf("stash failed: %w", err)
+		}
+	}
+
+	if request.Carry && *base != head.Hash() {
+		// go-git only keeps the changes when the commit does not change, git
+		// merges them into the new base or refuses when they conflict
+		err = runGit(dir, "switch", "-c", request.Name, base.String())
+	} else {
+		err = w.Checkout(&git.CheckoutOptions{
+			Branch: branch,
+			Hash:   *base,
+			Create: true,
+			Keep:   request.Carry,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	if hasRemote && config.Bool(PushAfterKey) {
+		progress(fmt.Sprintf("Pushing %s to %s...", request.Name, remote))
+		if err := runGit(dir, "push", "--set-upstream", remote, request.Name); err != nil {
+			return fmt.Errorf("created but the push failed: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	ui "github.com/awesome-gocui/gocui"
+	config "github.com/gookit/config/v2"
+)
+
+var (
+	// The texts offered by the Copy picker, in the order of its items
+	copyChoices []string
+
+	// Whether the Copy picker was opened from the Details view
+	copyFromDetails bool
+)
+
+// Open the selected issue in the browser. On the projects and statuses, the
+// project or the issues matching the current filter are opened, on a pull
+// request of the Development tab the pull request is opened
+func OpenInBrowser(g *ui.Gui, v *ui.View) error {
+	target := ""
+
+	switch v.Name() {
+	case DetailsView:
+		target = makeIssueURL(getActionIssueKey(v))
+		if ref := currentDetailsRef(); ref.Kind == DevLinkRef && ref.ID != "" {
+			target = ref.ID
+		}
+	case ProjectsView:
+		target = makeProjectURL(ProjectsList.CurrentItem())
+	case StatusesView:
+		target = makeSearchURL(StatusesList.code)
+	case IssuesView:
+		target = makeIssueURL(getActionIssueKey(v))
+		if target == "" {
+			target = makeSearchURL(IssuesList.code)
+		}
+	}
+
+	if target == "" {
+		return nil
+	}
+
+	if err := openExternally(target); err != nil {
+		ShowStatus(g, fmt.Sprintf("Failed to open %s: %s", target, err))
+		return nil
+	}
+
+	ShowStatus(g, fmt.Sprintf("Opened %s", target))
+
+	return nil
+}
+
+// Choose what to copy of the selected issue: its key, URL or key and summary
+func CopyIssuePrompt(g *ui.Gui, v *ui.View) error {
+	issueKey := getActionIssueKey(v)
+	if issueKey == "" {
+		return nil
+	}
+
+	summary := ""
+	if v.Name() == DetailsView {
+		summary = CurrentIssue.Fields.Summary
+	} else if issue := findListedIssue(issueKey); issue != nil && issue.Fields != nil {
+		summary = issue.Fields.Summary
+	}
+
+	copyChoices = []string{issueKey, makeIssueURL(issueKey)}
+	if summary != "" {
+		copyChoices = append(copyChoices, fmt.Sprintf("%s: %s", issueKey, summary))
+	}
+
+	copyFromDetails = v.Name() == DetailsView
+
+	IssuesList.Unfocus()
+	DetailsList.Unfocus()
+
+	createPickerView(g, CreateDialogOptions{title: fmt.Sprintf("%s| %s ", CopyTitle, issueKey)}, copyChoices)
+
+	return nil
+}
+
+// copyChoice is called with the index of the item chosen in the Copy picker
+func copyChoice(g *ui.Gui, index int) error {
+	focusAfterCopy(g)
+
+	if index >= len(copyChoices) {
+		return nil
+	}
+
+	text := copyChoices[index]
+	if err := copyToClipboard(text); err != nil {
+		ShowStatus(g, fmt.Sprintf("Failed to copy: %s", err))
+		return nil
+	}
+
+	ShowStatus(g, fmt.Sprintf("Copied %s", text))
+
+	return nil
+}
+
+func focusAfterCopy(g *ui.Gui) {
+	if copyFromDetails {
+		DetailsList.Focus(g)
+	} else {
+		IssuesList.Focus(g)
+	}
+}
+
+func getServerURL() string {
+	return strings.TrimRight(config.String(ServerKey), "/")
+}
+
+func makeIssueURL(issueKey string) string {
+	if issueKey == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s/browse/%s", getServerURL(), issueKey)
+}
+
+// makeProjectURL opens the project page, the entries which are not projects
+// (like "me") open the search of their issues instead
+func makeProjectURL(code string) string {
+	if code == "" {
+		return ""
+	}
+
+	if strings.EqualFold(code, AssignedToMeKey) || isBuiltinProject(code) {
+		return makeSearchURL(code)
+	}
+
+	return fmt.Sprintf("%s/browse/%s", getServerURL(), strings.ToUpper(code))
+}
+
+// makeSearchURL opens the issue search with the JQL used for the code
+func makeSearchURL(code string) string {
+	if code == "" {
+		return ""
+	}
+
+	jql := strings.TrimSpace(MakeJQL(code))
+
+	return fmt.Sprintf("%s/issues/?jql=%s", getServerURL(), url.QueryEscape(jql))
+}
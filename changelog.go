@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+)
+
+const AllFieldsChoice = "All fields"
+
+var (
+	// Only the changes of this field are displayed in the History tab, all
+	// of them when empty
+	changelogFilter string
+
+	// The fields changed in the history of the current issue
+	changelogFields []string
+)
+
+// makeChangelogLines is used by the History tab of the Details view, one line
+// per changed field e.g. "status: To Do → In Progress by Alice, 2h ago"
+func makeChangelogLines(issue *jira.Issue) *DetailsLines {
+	lines := &DetailsLines{}
+
+	histories, err := GetChangelog(issue.Key)
+	if err != nil {
+		lines.Add(DetailsRef{}, fmt.Sprintf("Failed to load the history: %s", err))
+		return lines
+	}
+
+	sort.SliceStable(histories, func(i, j int) bool {
+		return parseJiraTime(histories[i].Created).Before(parseJiraTime(histories[j].Created))
+	})
+
+	changelogFields = getChangelogFields(histories)
+
+	header := "All changes"
+	if changelogFilter != "" {
+		header = fmt.Sprintf("Changes of %s", changelogFilter)
+	}
+	lines.Add(DetailsRef{}, fmt.Sprintf("%s (press F to filter)", header), "")
+
+	width := DetailsList.width()
+	count := 0
+	for _, history := range histories {
+		for _, item := range history.Items {
+			if changelogFilter != "" && item.Field != changelogFilter {
+				continue
+			}
+
+			line := fmt.Sprintf("%s: %s → %s by %s, %s",
+				item.Field,
+				formatChangelogValue(item.FromString),
+				formatChangelogValue(item.ToString),
+				getUserName(&history.Author),
+				formatRelativeTime(parseJiraTime(history.Created)),
+			)
+			lines.Add(DetailsRef{}, wrapText(line, width)...)
+			count++
+		}
+	}
+
+	if count == 0 {
+		lines.Add(DetailsRef{}, "No changes")
+	}
+
+	return lines
+}
+
+// Choose the field whose changes are displayed in the History tab
+func FilterChangelog(g *ui.Gui, v *ui.View) error {
+	if CurrentIssue == nil || CurrentTab != HistoryTab {
+		return nil
+	}
+
+	DetailsList.Unfocus()
+
+	items := append([]string{AllFieldsChoice}, changelogFields...)
+	createPickerView(g, CreateDialogOptions{title: ChangelogTitle}, items)
+
+	for index, field := range items {
+		if field == changelogFilter {
+			return PickerList.SelectIndex(index)
+		}
+	}
+
+	return nil
+}
+
+// applyChangelogFilter is called with the index of the item chosen in the
+// filter picker, the first one shows all the fields
+func applyChangelogFilter(g *ui.Gui, index int) error {
+	changelogFilter = ""
+	if index > 0 && index <= len(changelogFields) {
+		changelogFilter = changelogFields[index-1]
+	}
+
+	DetailsList.Focus(g)
+	DetailsList.Reset()
+	renderDetails(g)
+
+	return nil
+}
+
+// getChangelogFields lists the changed fields once, sorted by name
+func getChangelogFields(histories []jira.ChangelogHistory) []string {
+	seen := make(map[string]bool)
+	fields := make([]string, 0)
+	for _, history := range histories {
+		for _, item := range history.Items {
+			if !seen[item.Field] {
+				seen[item.Field] = true
+				fields = append(fields, item.Field)
+			}
+		}
+	}
+
+	sort.Strings(fields)
+
+	return fields
+}
+
+// formatChangelogValue keeps long values, like the description, on one line
+func formatChangelogValue(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return "-"
+	}
+
+	return truncate(value, 40)
+}
+
+// formatRelativeTime writes how long ago it was, e.g. "2h ago", the date is
+// used after a month
+func formatRelativeTime(t time.Time) string {
+	if t.IsZero() {
+		return "at an unknown date"
+	}
+
+	elapsed := time.Since(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	case elapsed < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24))
+	}
+
+	return fmt.Sprintf("on %s", t.Local().Format(DateLayout))
+}
+
+// parseJiraTime reads the dates returned by the API, e.g.
+// "2024-05-13T10:24:00.000+0200"
+func parseJiraTime(value string) time.Time {
+	date, err := time.Parse("2006-01-02T15:04:05.999-0700", value)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return date
+}
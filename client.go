@@ -3,8 +3,14 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	jira "github.com/andygrunwald/go-jira/v2/cloud"
 )
@@ -50,6 +56,14 @@ func MakeJQL(rawCode string) string {
 		return fmt.Sprintf("assignee=currentUser() %s", statusQL)
 	}
 
+	if code == WatchingKey {
+		return fmt.Sprintf("watcher = currentUser() %s", statusQL)
+	}
+
+	if code == RecentKey {
+		return makeRecentJQL(statusQL)
+	}
+
 	return fmt.Sprintf("project IN (%s) %s", code, statusQL)
 }
 
@@ -59,6 +73,10 @@ func SearchIssuesByProjectCode(projectCode string) ([]jira.Issue, error) {
 	// Define JQL query
 	jql := MakeJQL(projectCode)
 
+	if strings.EqualFold(projectCode, RecentKey) {
+		return searchRecentIssues(jql)
+	}
+
 	// Get list of issues
 	issues, _, err := client.Issue.Search(context.Background(), jql, nil)
 	if err != nil {
@@ -68,6 +86,524 @@ func SearchIssuesByProjectCode(projectCode string) ([]jira.Issue, error) {
 	return issues, nil
 }
 
+// searchRecentIssues does not fail on the keys opened locally which do not
+// exist anymore, they are only reported as warnings by Jira
+func searchRecentIssues(jql string) ([]jira.Issue, error) {
+	client, _ := GetJiraClient()
+
+	options := &jira.SearchOptions{MaxResults: RecentIssuesLimit * 2, ValidateQuery: "warn"}
+	issues, _, err := client.Issue.Search(context.Background(), jql, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortRecentIssues(issues), nil
+}
+
+// SearchChildIssues fetches the children of the given epics, the keys are sent
+// in batches so a big project does not need one request per epic
+func SearchChildIssues(parentKeys []string) ([]jira.Issue, error) {
+	client, _ := GetJiraClient()
+
+	children := make([]jira.Issue, 0)
+	for start := 0; start < len(parentKeys); start += ChildrenBatchSize {
+		end := start + ChildrenBatchSize
+		if end > len(parentKeys) {
+			end = len(parentKeys)
+		}
+
+		joined := strings.Join(parentKeys[start:end], ",")
+		jql := fmt.Sprintf("parent IN (%s) OR %s IN (%s)", joined, getEpicLinkClause(), joined)
+
+		err := client.Issue.SearchPages(context.Background(), jql, nil, func(issue jira.Issue) error {
+			children = append(children, issue)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return children, nil
+}
+
+func GetIssueByKey(key string) (*jira.Issue, error) {
+	client, _ := GetJiraClient()
+
+	issue, _, err := client.Issue.Get(context.Background(), key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return issue, nil
+}
+
+// SearchIssuesByKeys loads several issues at once, only with the requested fields
+func SearchIssuesByKeys(keys []string, fields []string) ([]jira.Issue, error) {
+	client, _ := GetJiraClient()
+
+	issues := make([]jira.Issue, 0, len(keys))
+	for start := 0; start < len(keys); start += ChildrenBatchSize {
+		end := start + ChildrenBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		jql := fmt.Sprintf("issuekey IN (%s)", strings.Join(keys[start:end], ","))
+		options := &jira.SearchOptions{MaxResults: ChildrenBatchSize, Fields: fields}
+
+		err := client.Issue.SearchPages(context.Background(), jql, options, func(issue jira.Issue) error {
+			issues = append(issues, issue)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return issues, nil
+}
+
+func GetIssueLinkTypes() ([]jira.IssueLinkType, error) {
+	client, _ := GetJiraClient()
+
+	linkTypes, _, err := client.IssueLinkType.GetList(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return linkTypes, nil
+}
+
+// CreateIssueLink links the issue to the target with the chosen description,
+// Jira reads the link as "inward issue <outward description> outward issue"
+func CreateIssueLink(issueKey string, targetKey string, choice LinkChoice) error {
+	client, _ := GetJiraClient()
+
+	link := &jira.IssueLink{
+		Type:         jira.IssueLinkType{Name: choice.TypeName},
+		InwardIssue:  &jira.Issue{Key: targetKey},
+		OutwardIssue: &jira.Issue{Key: issueKey},
+	}
+	if choice.Outward {
+		link.InwardIssue, link.OutwardIssue = link.OutwardIssue, link.InwardIssue
+	}
+
+	_, err := client.Issue.AddLink(context.Background(), link)
+
+	return err
+}
+
+func DeleteIssueLink(linkID string) error {
+	client, _ := GetJiraClient()
+
+	_, err := client.Issue.DeleteLink(context.Background(), linkID)
+
+	return err
+}
+
+// The current user does not change while the app is running, so it is only
+// fetched once
+var currentUser *jira.User
+
+func GetCurrentUser() (*jira.User, error) {
+	if currentUser != nil {
+		return currentUser, nil
+	}
+
+	client, _ := GetJiraClient()
+
+	user, _, err := client.User.GetCurrentUser(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	currentUser = user
+
+	return currentUser, nil
+}
+
+// The users mentioned in the texts, by account id, each one is only fetched once
+var mentionedUsers = make(map[string]*jira.User)
+
+func GetUser(accountID string) (*jira.User, error) {
+	if user, ok := mentionedUsers[accountID]; ok {
+		return user, nil
+	}
+
+	client, _ := GetJiraClient()
+
+	user, _, err := client.User.Get(context.Background(), url.QueryEscape(accountID))
+	if err != nil {
+		return nil, err
+	}
+	mentionedUsers[accountID] = user
+
+	return user, nil
+}
+
+// SearchAssignableUsers lists the users who can be assigned to the issue and
+// match the query (name or email)
+func SearchAssignableUsers(issueKey string, query string) ([]jira.User, error) {
+	client, _ := GetJiraClient()
+
+	params := url.Values{}
+	params.Set("issueKey", issueKey)
+	params.Set("query", query)
+	params.Set("maxResults", fmt.Sprint(AssignableUsersLimit))
+
+	endpoint := fmt.Sprintf("rest/api/2/user/assignable/search?%s", params.Encode())
+	req, err := client.NewRequest(context.Background(), http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]jira.User, 0)
+	resp, err := client.Do(req, &users)
+	if err != nil {
+		return nil, jira.NewJiraError(resp, err)
+	}
+
+	return users, nil
+}
+
+// AssignIssue assigns the issue to the account, an empty account id unassigns it
+func AssignIssue(issueKey string, accountID string) error {
+	client, _ := GetJiraClient()
+
+	body := map[string]interface{}{"accountId": nil}
+	if accountID != "" {
+		body["accountId"] = accountID
+	}
+
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/assignee", issueKey)
+	req, err := client.NewRequest(context.Background(), http.MethodPut, endpoint, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req, nil)
+	if err != nil {
+		return jira.NewJiraError(resp, err)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// GetEditMeta returns the fields of the issue which can be edited, by field id
+func GetEditMeta(issueKey string) (map[string]FieldMeta, error) {
+	client, _ := GetJiraClient()
+
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/editmeta", issueKey)
+	req, err := client.NewRequest(context.Background(), http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := struct {
+		Fields map[string]FieldMeta `json:"fields"`
+	}{}
+	resp, err := client.Do(req, &meta)
+	if err != nil {
+		return nil, jira.NewJiraError(resp, err)
+	}
+
+	return meta.Fields, nil
+}
+
+func UpdateIssueFields(issueKey string, fields map[string]interface{}) error {
+	client, _ := GetJiraClient()
+
+	resp, err := client.Issue.UpdateIssue(context.Background(), issueKey, map[string]interface{}{
+		"fields": fields,
+	})
+	if err != nil {
+		return jira.NewJiraError(resp, err)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+func GetWorklogs(issueKey string) ([]jira.WorklogRecord, error) {
+	client, _ := GetJiraClient()
+
+	worklog, resp, err := client.Issue.GetWorklogs(context.Background(), issueKey)
+	if err != nil {
+		return nil, jira.NewJiraError(resp, err)
+	}
+
+	return worklog.Worklogs, nil
+}
+
+func AddWorklog(issueKey string, record *jira.WorklogRecord) error {
+	client, _ := GetJiraClient()
+
+	// The service already turned the response into the error
+	_, _, err := client.Issue.AddWorklogRecord(context.Background(), issueKey, record)
+
+	return err
+}
+
+func UpdateWorklog(issueKey string, worklogID string, record *jira.WorklogRecord) error {
+	client, _ := GetJiraClient()
+
+	_, _, err := client.Issue.UpdateWorklogRecord(context.Background(), issueKey, worklogID, record)
+
+	return err
+}
+
+// DeleteWorklog removes the worklog, the remaining estimate is adjusted
+// automatically by Jira
+func DeleteWorklog(issueKey string, worklogID string) error {
+	client, _ := GetJiraClient()
+
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/worklog/%s", issueKey, worklogID)
+	req, err := client.NewRequest(context.Background(), http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req, nil)
+	if err != nil {
+		return jira.NewJiraError(resp, err)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// DownloadAttachment starts the download, the caller reads and closes the body
+func DownloadAttachment(attachmentID string) (*http.Response, error) {
+	client, _ := GetJiraClient()
+
+	resp, err := client.Issue.DownloadAttachment(context.Background(), attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Response, nil
+}
+
+// GetAttachmentHead reads the first bytes of the attachment, enough to find
+// the dimensions of an image without downloading it
+func GetAttachmentHead(contentURL string, length int) ([]byte, error) {
+	client, _ := GetJiraClient()
+
+	req, err := client.NewRequest(context.Background(), http.MethodGet, contentURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", length-1))
+
+	resp, err := client.Do(req, nil)
+	if err != nil {
+		return nil, jira.NewJiraError(resp, err)
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(io.LimitReader(resp.Body, int64(length)))
+}
+
+func UploadAttachment(issueKey string, path string) error {
+	client, _ := GetJiraClient()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// The service already turned the response into the error
+	_, _, err = client.Issue.PostAttachment(context.Background(), issueKey, file, filepath.Base(path))
+
+	return err
+}
+
+// SearchWorklogIssues finds the issues the current user logged work on
+// between the two dates, both included
+func SearchWorklogIssues(from time.Time, to time.Time) ([]jira.Issue, error) {
+	client, _ := GetJiraClient()
+
+	jql := fmt.Sprintf(`worklogAuthor = currentUser() AND worklogDate >= "%s" AND worklogDate <= "%s" ORDER BY key`,
+		from.Format(DateLayout), to.Format(DateLayout))
+	options := &jira.SearchOptions{MaxResults: ChildrenBatchSize, Fields: []string{"summary"}}
+
+	issues := make([]jira.Issue, 0)
+	err := client.Issue.SearchPages(context.Background(), jql, options, func(issue jira.Issue) error {
+		issues = append(issues, issue)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return issues, nil
+}
+
+func AddWatcher(issueKey string, accountID string) error {
+	client, _ := GetJiraClient()
+
+	// The service already turned the response into the error
+	resp, err := client.Issue.AddWatcher(context.Background(), issueKey, accountID)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// RemoveWatcher passes the account in the query, the client sends it in the
+// body which Jira Cloud ignores
+func RemoveWatcher(issueKey string, accountID string) error {
+	client, _ := GetJiraClient()
+
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/watchers?accountId=%s", issueKey, url.QueryEscape(accountID))
+	req, err := client.NewRequest(context.Background(), http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req, nil)
+	if err != nil {
+		return jira.NewJiraError(resp, err)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+func AddVote(issueKey string) error {
+	return sendVoteRequest(issueKey, http.MethodPost)
+}
+
+func RemoveVote(issueKey string) error {
+	return sendVoteRequest(issueKey, http.MethodDelete)
+}
+
+func sendVoteRequest(issueKey string, method string) error {
+	client, _ := GetJiraClient()
+
+	endpoint := fmt.Sprintf("rest/api/2/issue/%s/votes", issueKey)
+	req, err := client.NewRequest(context.Background(), method, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req, nil)
+	if err != nil {
+		return jira.NewJiraError(resp, err)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// GetChangelog fetches all the changes of the issue page by page, the
+// changelog expanded on the issue stops at the 100 most recent ones
+func GetChangelog(issueKey string) ([]jira.ChangelogHistory, error) {
+	client, _ := GetJiraClient()
+
+	histories := make([]jira.ChangelogHistory, 0)
+	for {
+		endpoint := fmt.Sprintf("rest/api/2/issue/%s/changelog?startAt=%d&maxResults=%d", issueKey, len(histories), ChangelogPageSize)
+		req, err := client.NewRequest(context.Background(), http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		page := struct {
+			Values []jira.ChangelogHistory `json:"values"`
+			Total  int                     `json:"total"`
+			IsLast bool                    `json:"isLast"`
+		}{}
+		resp, err := client.Do(req, &page)
+		if err != nil {
+			return nil, jira.NewJiraError(resp, err)
+		}
+
+		histories = append(histories, page.Values...)
+		if page.IsLast || len(page.Values) == 0 || len(histories) >= page.Total {
+			return histories, nil
+		}
+	}
+}
+
+// GetTransitions lists the transitions the current user can make on the issue
+// from its current status
+func GetTransitions(issueKey string) ([]jira.Transition, error) {
+	client, _ := GetJiraClient()
+
+	// The service already turned the response into the error
+	transitions, _, err := client.Issue.GetTransitions(context.Background(), issueKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return transitions, nil
+}
+
+func TransitionIssue(issueKey string, transitionID string) error {
+	client, _ := GetJiraClient()
+
+	// The service already turned the response into the error
+	resp, err := client.Issue.DoTransition(context.Background(), issueKey, transitionID)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// GetDevStatusSummary fetches the counts of the development information of
+// the issue, per kind (pullrequest, branch, repository, build) and per
+// application (GitHub, Bitbucket...). The dev-status API takes the numeric
+// id of the issue, not its key
+func GetDevStatusSummary(issueID string) (*DevStatusSummary, error) {
+	client, _ := GetJiraClient()
+
+	endpoint := fmt.Sprintf("rest/dev-status/latest/issue/summary?issueId=%s", url.QueryEscape(issueID))
+	req, err := client.NewRequest(context.Background(), http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &DevStatusSummary{}
+	resp, err := client.Do(req, summary)
+	if err != nil {
+		return nil, jira.NewJiraError(resp, err)
+	}
+
+	return summary, nil
+}
+
+// GetDevStatusDetail fetches the development information of one kind from
+// one application, e.g. the pull requests from GitHub
+func GetDevStatusDetail(issueID string, applicationType string, dataType string) ([]DevStatusDetail, error) {
+	client, _ := GetJiraClient()
+
+	params := url.Values{}
+	params.Set("issueId", issueID)
+	params.Set("applicationType", applicationType)
+	params.Set("dataType", dataType)
+
+	endpoint := fmt.Sprintf("rest/dev-status/latest/issue/detail?%s", params.Encode())
+	req, err := client.NewRequest(context.Background(), http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DevStatusDetailResponse{}
+	resp, err := client.Do(req, result)
+	if err != nil {
+		return nil, jira.NewJiraError(resp, err)
+	}
+
+	return result.Detail, nil
+}
+
 func SearchStatusesByProjectCode(projectCode string) ([]jira.Status, []jira.Issue, error) {
 	issues, err := SearchIssuesByProjectCode(projectCode)
 	if err != nil {
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// The commands tried in order to write into the system clipboard
+var clipboardCommands = [][]string{
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+}
+
+// copyToClipboard writes the text into the system clipboard. Without any
+// clipboard command (e.g. over SSH) the OSC52 escape sequence asks the
+// terminal to do it
+func copyToClipboard(text string) error {
+	commands := clipboardCommands
+	if runtime.GOOS == "darwin" {
+		commands = [][]string{{"pbcopy"}}
+	}
+
+	if os.Getenv("SSH_TTY") == "" {
+		for _, command := range commands {
+			if _, err := exec.LookPath(command[0]); err != nil {
+				continue
+			}
+
+			cmd := exec.Command(command[0], command[1:]...)
+			cmd.Stdin = strings.NewReader(text)
+			if err := cmd.Run(); err == nil {
+				return nil
+			}
+		}
+	}
+
+	return copyWithOSC52(text)
+}
+
+func copyWithOSC52(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer tty.Close()
+
+	sequence := fmt.Sprintf("\x1b]52;c;%s\x07", base64.StdEncoding.EncodeToString([]byte(text)))
+
+	// tmux only forwards the sequence to the terminal when it is wrapped
+	if os.Getenv("TMUX") != "" {
+		sequence = fmt.Sprintf("\x1bPtmux;\x1b%s\x1b\\", sequence)
+	}
+
+	_, err = tty.WriteString(sequence)
+
+	return err
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const CommandsUsage = `Usage: lazyjira [command]
+
+Without command, the terminal UI is started.
+
+Commands:
+  timesheet   Print the time logged by you, per issue and per day
+  hook        Install or uninstall the git hook prefixing the commit messages
+              with the issue key of the branch: hook install [--force]
+`
+
+// runCommand runs the command given on the command line instead of the UI,
+// it returns the exit code
+func runCommand(args []string) int {
+	switch args[0] {
+	case "timesheet":
+		return runTimesheetCommand(args[1:])
+	case "hook":
+		return runHookCommand(args[1:])
+	case "help", "-h", "--help":
+		fmt.Print(CommandsUsage)
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", args[0], CommandsUsage)
+
+	return 2
+}
+
+func runTimesheetCommand(args []string) int {
+	initConfigSetup()
+
+	flags := flag.NewFlagSet("timesheet", flag.ContinueOnError)
+	from := flags.String("from", "", "first day, e.g. 2024-05-13 (default: Monday of the current week)")
+	to := flags.String("to", "", "last day, included (default: 6 days after --from)")
+	format := flags.String("format", "grid", "output format: grid, csv or markdown")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	start, end, err := parseTimesheetRange(*from, *to)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	timesheet, err := BuildTimesheet(start, end)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to build the timesheet:", err)
+		return 1
+	}
+
+	switch strings.ToLower(*format) {
+	case "csv":
+		err = timesheet.WriteCSV(os.Stdout)
+	case "markdown", "md":
+		err = timesheet.WriteMarkdown(os.Stdout)
+	case "grid":
+		_, err = fmt.Println(strings.Join(timesheet.Lines(), "\n"))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format %q, expected grid, csv or markdown\n", *format)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func runHookCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Missing hook command, expected install or uninstall\n")
+		return 2
+	}
+
+	switch args[0] {
+	case "install":
+		flags := flag.NewFlagSet("hook install", flag.ContinueOnError)
+		force := flags.Bool("force", false, "replace an existing hook")
+
+		if err := flags.Parse(args[1:]); err != nil {
+			return 2
+		}
+
+		path, err := installCommitHook(".", *force)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to install the hook:", err)
+			return 1
+		}
+
+		fmt.Println("Installed", path)
+	case "uninstall":
+		path, err := uninstallCommitHook(".")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to uninstall the hook:", err)
+			return 1
+		}
+
+		fmt.Println("Removed", path)
+	case PrepareCommitMsgHook:
+		// Run by the installed hook with the arguments of git
+		if len(args) < 2 {
+			return 2
+		}
+
+		// Without a config file, the default key pattern is used
+		_ = loadConfig()
+
+		source := ""
+		if len(args) > 2 {
+			source = args[2]
+		}
+
+		if err := prepareCommitMessage(".", args[1], source); err != nil {
+			fmt.Fprintln(os.Stderr, "lazyjira:", err)
+			return 1
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown hook command %q, expected install or uninstall\n", args[0])
+		return 2
+	}
+
+	return 0
+}
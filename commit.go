@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+	config "github.com/gookit/config/v2"
+)
+
+// Used when neither git.commitTemplate nor the project define a template
+const DefaultCommitTemplate = `{{.Key}} {{.Summary}}`
+
+var (
+	// The list focused before the Commit prompt was opened
+	commitReturnList *List
+
+	// Bumped on every submission of the Commit prompt, the checks of an older
+	// submission are dropped
+	commitSeed int
+)
+
+// Commit the staged changes with a message made from the commit template of
+// the selected issue
+func CommitPrompt(g *ui.Gui, v *ui.View) error {
+	issue := getActionIssue(v)
+	if issue == nil {
+		return nil
+	}
+
+	projectCode, _, _ := strings.Cut(issue.Key, "-")
+
+	return selectRepository(g, v, projectCode, func(g *ui.Gui) error {
+		return commitPrompt(g, v, issue)
+	})
+}
+
+func commitPrompt(g *ui.Gui, v *ui.View, issue *jira.Issue) error {
+	staged, err := getStagedFiles(gitRepoPath)
+	if err != nil {
+		ShowStatus(g, fmt.Sprintf("Failed to read the staged changes: %s", err))
+		return nil
+	}
+	if len(staged) == 0 {
+		ShowStatus(g, "Nothing is staged, stage your changes with git add first")
+		return nil
+	}
+
+	message, err := makeCommitMessage(issue)
+	if err != nil {
+		ShowStatus(g, err.Error())
+		return nil
+	}
+
+	commitReturnList = IssuesList
+	if v.Name() == DetailsView {
+		commitReturnList = DetailsList
+	}
+	commitReturnList.Unfocus()
+
+	createPromptView(g, CreateDialogOptions{
+		title:   fmt.Sprintf("%s| %s | %d files ", CommitTitle, issue.Key, len(staged)),
+		content: message,
+		value:   issue.Key,
+	})
+
+	return PromptDialog.SetCursor(len(message), 0)
+}
+
+// submitCommit checks the smart commit commands in background, the prompt
+// stays open when one of them is wrong
+func submitCommit(g *ui.Gui, message string) error {
+	commitSeed++
+	seed := commitSeed
+
+	prompt := PromptDialog.View
+	issueKey := PromptDialog.value
+	projects := GetSavedProjects()
+
+	if len(parseSmartCommands(message)) > 0 {
+		PromptDialog.Subtitle = " Checking the smart commit commands... "
+	}
+
+	go func() {
+		err := validateSmartCommit(message, issueKey, projects)
+
+		g.Update(func(g *ui.Gui) error {
+			// The prompt was closed, or submitted again, in the meantime
+			if v, viewErr := g.View(PromptView); viewErr != nil || v != prompt || seed != commitSeed {
+				return nil
+			}
+
+			if err != nil {
+				PromptDialog.Subtitle = fmt.Sprintf(" %s ", err)
+				return nil
+			}
+
+			deletePromptView(g)
+			focusAfterCommit(g)
+
+			return runCommit(g, message)
+		})
+	}()
+
+	return nil
+}
+
+// runCommit runs git commit in the background, so the hooks of the repository
+// are run as usual
+func runCommit(g *ui.Gui, message string) error {
+	title, _, _ := strings.Cut(message, "\n")
+	ShowStatus(g, fmt.Sprintf("Committing %s...", title))
+
+	path := gitRepoPath
+	go func() {
+		err := runGit(path, "commit", "-m", message)
+
+		g.Update(func(g *ui.Gui) error {
+			if err != nil {
+				ShowStatus(g, fmt.Sprintf("Failed to commit: %s", err))
+				return nil
+			}
+
+			ShowStatus(g, fmt.Sprintf("Committed %s", title))
+
+			if CurrentIssue != nil {
+				forgetTabsData(CurrentIssue.Key)
+				if CurrentTab == GitTab {
+					renderDetails(g)
+				}
+			}
+
+			return nil
+		})
+	}()
+
+	return nil
+}
+
+func focusAfterCommit(g *ui.Gui) {
+	if commitReturnList != nil {
+		commitReturnList.Focus(g)
+	}
+}
+
+// makeCommitMessage renders the commit template of the issue project, e.g.
+// projects.abc.commitTemplate, or git.commitTemplate. It can use the same
+// data and functions as the branch templates
+func makeCommitMessage(issue *jira.Issue) (string, error) {
+	projectCode, _, _ := strings.Cut(issue.Key, "-")
+
+	text := config.String(fmt.Sprintf("%s.%s.commitTemplate", ProjectsKey, strings.ToLower(projectCode)))
+	if text == "" {
+		text = config.String(CommitTemplateKey, DefaultCommitTemplate)
+	}
+
+	tmpl, err := template.New("commit").Funcs(branchTemplateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit template: %w", err)
+	}
+
+	var message bytes.Buffer
+	if err := tmpl.Execute(&message, makeBranchTemplateData(issue)); err != nil {
+		return "", fmt.Errorf("invalid commit template: %w", err)
+	}
+
+	return strings.TrimSpace(message.String()), nil
+}
+
+// getStagedFiles lists the files whose changes are staged for the next
+// commit
+func getStagedFiles(repoPath string) ([]string, error) {
+	output, err := runGitOutput(repoPath, "diff", "--cached", "--name-only")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0)
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+
+	return files, nil
+}
@@ -3,11 +3,35 @@ package main
 const (
 	ProjectName = "lazyjira"
 
-	ProjectsKey     = "projects"
-	AssignedToMeKey = "me"
-	ServerKey       = "server"
-	UsernameKey     = "username"
-	GitPrefixKey    = "prefix"
+	ProjectsKey         = "projects"
+	AssignedToMeKey     = "me"
+	WatchingKey         = "watching"
+	RecentKey           = "recent"
+	ServerKey           = "server"
+	UsernameKey         = "username"
+	GitPrefixKey        = "prefix"
+	BranchTemplateKey   = "git.branchTemplate"
+	IssueKeyPatternKey  = "git.issueKeyPattern"
+	DefaultBranchKey    = "git.defaultBranch"
+	GitRemoteKey        = "git.remote"
+	FetchBeforeKey      = "git.fetchBeforeCreate"
+	PushAfterKey        = "git.pushAfterCreate"
+	WorktreeDirKey      = "git.worktreeDir"
+	CommitTemplateKey   = "git.commitTemplate"
+	OnBranchCreateKey   = "git.onBranchCreate"
+	EpicLinkFieldKey    = "epicLinkField"
+	StoryPointsFieldKey = "storyPointsField"
+	DownloadDirKey      = "attachments.downloadDir"
+	OpenDownloadsKey    = "attachments.openAfterDownload"
+
+	DefaultEpicLinkField    = "customfield_10014"
+	DefaultStoryPointsField = "customfield_10016"
+	DefaultGitRemote        = "origin"
+	DateLayout              = "2006-01-02"
+	DateTimeLayout          = "2006-01-02 15:04"
+	ChildrenBatchSize       = 50
+	ChangelogPageSize       = 100
+	AssignableUsersLimit    = 20
 
 	ConfigPathMsg = "~/.config/lazyjira/config.yaml"
 	HelpLinkMsg   = "https://github.com/sangdth/lazyjira#getting-started"
@@ -18,6 +42,31 @@ const (
 	InsertServerTitle   = " Enter your server address "
 	DeleteConfirmTitle  = " Are you sure? "
 	NewBranchTitle      = " Create Git Branch "
+	LinkTypeTitle       = " Choose link type "
+	NewLinkTitle        = " Link to issue "
+	RemoveLinkTitle     = " Remove link? "
+	SuggestionsTitle    = " Suggestions "
+	AssignTitle         = " Assign to "
+	AssignErrorTitle    = " Failed to assign "
+	EditFieldTitle      = " Edit field "
+	LogWorkTitle        = " Log work "
+	EditWorklogTitle    = " Edit worklog "
+	DeleteWorklogTitle  = " Delete worklog? "
+	TimesheetTitle      = " Timesheet "
+	AttachFileTitle     = " Attach file "
+	ChangelogTitle      = " Filter history "
+	CopyTitle           = " Copy "
+	JumpToIssueTitle    = " Go to issue "
+	CheckoutBranchTitle = " Check out branch "
+	BaseBranchTitle     = " Start the branch from "
+	BaseRefTitle        = " Base ref "
+	DirtyTreeTitle      = " Uncommitted changes "
+	RepoPickerTitle     = " Choose repository "
+	WorktreeTitle       = " Worktrees "
+	CommitTitle         = " Commit staged changes "
 
-	DialogDescription = " Press <Enter> to continue, <Esc> to cancel "
+	DialogDescription    = " Press <Enter> to continue, <Esc> to cancel "
+	PickerDescription    = " Press <Enter> to choose, <Esc> to cancel "
+	TimesheetDescription = " Press <h>/<l> to change week, <Esc> to close "
+	PromptDescription    = " Press <Enter> to continue, <Ctrl-E> to open $EDITOR, <Esc> to cancel "
 )
@@ -6,8 +6,6 @@ import (
 	"strings"
 
 	ui "github.com/awesome-gocui/gocui"
-	git "github.com/go-git/go-git/v5"
-	plumbing "github.com/go-git/go-git/v5/plumbing"
 	config "github.com/gookit/config/v2"
 )
 
@@ -38,14 +36,19 @@ func createPromptView(g *ui.Gui, o CreateDialogOptions) {
 	g.Cursor = true
 
 	PromptDialog = CreateDialog(v, PROMPT)
-	PromptDialog.SetTitles(o.title, DialogDescription)
+	PromptDialog.SetTitles(o.title, PromptDescription)
 	PromptDialog.SetContent(o.content)
 	PromptDialog.SetValue(o.value)
 	PromptDialog.Focus(g)
+
+	if o.suggest != nil {
+		createSuggestionsView(g, o.suggest)
+	}
 }
 
 func deletePromptView(g *ui.Gui) {
 	g.Cursor = false
+	deleteSuggestionsView(g)
 	if err := g.DeleteView(PromptView); err != nil {
 		log.Panicln("Error while deleting prompt view", err)
 	}
@@ -91,9 +94,24 @@ func CancelDialog(g *ui.Gui, v *ui.View) error {
 		if _, err := g.View(ProjectsView); err == nil && isNewProjectView(v) {
 			ProjectsList.Focus(g)
 		}
-		if _, err := g.View(IssuesView); err == nil && isCreatingBranchView(v) {
+		if _, err := g.View(IssuesView); err == nil && (isCreatingBranchView(v) || isBaseRefView(v)) {
+			IssuesList.Focus(g)
+		}
+		if isAssignView(v) {
 			IssuesList.Focus(g)
 		}
+		if isNewLinkView(v) || isEditFieldView(v) || isEditWorklogView(v) || isAttachFileView(v) {
+			DetailsList.Focus(g)
+		}
+		if isLogWorkView(v) {
+			focusAfterLogWork(g)
+		}
+		if isJumpToIssueView(v) {
+			cancelJumpToIssue(g)
+		}
+		if isCommitView(v) {
+			focusAfterCommit(g)
+		}
 
 		deletePromptView(g)
 
@@ -103,10 +121,29 @@ func CancelDialog(g *ui.Gui, v *ui.View) error {
 		deleteAlertView(g)
 		if _, err := g.View(PromptView); err == nil {
 			PromptDialog.Focus(g)
+		} else if isRemoveLinkView(v) || isDeleteWorklogView(v) {
+			DetailsList.Focus(g)
+		} else if isAssignErrorView(v) {
+			IssuesList.Focus(g)
 		} else {
 			ProjectsList.Focus(g)
 		}
 		return nil
+
+	case PickerView:
+		deletePickerView(g)
+		if isLinkTypePickerView(v) || isEditFieldView(v) || isChangelogFilterView(v) {
+			DetailsList.Focus(g)
+		} else if isCopyView(v) {
+			focusAfterCopy(g)
+		} else if isCheckoutBranchView(v) || isBaseBranchView(v) || isDirtyTreeView(v) {
+			IssuesList.Focus(g)
+		} else if isRepoPickerView(v) {
+			cancelRepositoryPicker(g)
+		} else if isWorktreeView(v) {
+			cancelWorktreePicker(g)
+		}
+		return nil
 	}
 	return nil
 }
@@ -118,6 +155,26 @@ func SubmitPrompt(g *ui.Gui, v *ui.View) error {
 	}
 
 	g.Update(func(g *ui.Gui) error {
+		if isLogWorkView(v) {
+			return submitWorklog(g, value)
+		}
+
+		if isEditWorklogView(v) {
+			return submitEditedWorklog(g, value)
+		}
+
+		if isAttachFileView(v) {
+			return submitAttachment(g, value)
+		}
+
+		if isJumpToIssueView(v) {
+			return submitJumpToIssue(g, value)
+		}
+
+		if isCommitView(v) {
+			return submitCommit(g, value)
+		}
+
 		if isNewUsernameView(v) {
 			if err := config.Set(UsernameKey, value); err != nil {
 				log.Panicln("Error while init username", err)
@@ -192,47 +249,75 @@ func SubmitPrompt(g *ui.Gui, v *ui.View) error {
 			return nil
 		}
 
-		if isCreatingBranchView(v) {
-			repo, err := git.PlainOpen(".")
-			if err != nil {
-				log.Fatal(err)
+		if isNewLinkView(v) {
+			targetKey := issueKeyFromRow(strings.ToUpper(value))
+			if targetKey == "" {
+				createAlertView(g, CreateDialogOptions{
+					title:   " Alert! ",
+					content: fmt.Sprintf("%s is not an issue key", value),
+				})
+
+				return nil
 			}
 
-			// Get the HEAD reference
-			headRef, err := repo.Head()
-			if err != nil {
-				log.Fatal(err)
+			issueKey := PromptDialog.value
+			if err := CreateIssueLink(issueKey, targetKey, selectedLinkChoice); err != nil {
+				createAlertView(g, CreateDialogOptions{
+					title:   " Alert! ",
+					content: err.Error(),
+				})
+
+				return nil
 			}
 
-			// Create a new reference for the new branch
-			newBranchRef := plumbing.NewBranchReferenceName(value)
+			deletePromptView(g)
+			DetailsList.Focus(g)
 
-			// Create a new branch from the current commit
-			newBranch := plumbing.NewHashReference(newBranchRef, headRef.Hash())
+			return OpenIssue(g, issueKey, false)
+		}
 
-			// Update the references in the repository
-			err = repo.Storer.SetReference(newBranch)
+		if isEditFieldView(v) {
+			fieldValue, err := parseFieldInput(value)
 			if err != nil {
-				log.Fatal(err)
+				fieldErrors[editingField.Ref] = err.Error()
 			}
 
-			// Checkout the new branch
-			w, err := repo.Worktree()
+			deletePromptView(g)
+
 			if err != nil {
-				log.Fatal(err)
+				DetailsList.Focus(g)
+				renderDetails(g)
+				return nil
 			}
 
-			err = w.Checkout(&git.CheckoutOptions{
-				Branch: newBranchRef,
-			})
-			if err != nil {
-				log.Fatal(err)
+			return saveEditingField(g, fieldValue)
+		}
+
+		if isAssignView(v) {
+			issueKey := PromptDialog.value
+
+			user := findSuggestion(g, value)
+			if user == nil {
+				PromptDialog.Subtitle = " Unknown user, pick one with ↑ ↓ or Tab "
+				return nil
 			}
 
 			deletePromptView(g)
 			IssuesList.Focus(g)
 
-			return nil
+			if err := AssignIssue(issueKey, user.ID); err != nil {
+				return showAssignError(g, err)
+			}
+
+			return refreshAfterAssign(g, issueKey)
+		}
+
+		if isCreatingBranchView(v) {
+			return submitBranchName(g, value)
+		}
+
+		if isBaseRefView(v) {
+			return submitBaseRef(g, value)
 		}
 
 		return nil
@@ -249,6 +334,33 @@ func SubmitAlert(g *ui.Gui, v *ui.View) error {
 	}
 
 	g.Update(func(g *ui.Gui) error {
+		if isDeleteWorklogView(v) {
+			if err := DeleteWorklog(CurrentIssue.Key, value); err != nil {
+				AlertDialog.Clear()
+				AlertDialog.SetContent(err.Error())
+
+				return nil
+			}
+			deleteAlertView(g)
+			DetailsList.Focus(g)
+			ShowStatus(g, "Worklog deleted")
+
+			return OpenIssue(g, CurrentIssue.Key, false)
+		}
+
+		if isRemoveLinkView(v) {
+			if err := DeleteIssueLink(value); err != nil {
+				AlertDialog.Clear()
+				AlertDialog.SetContent(err.Error())
+
+				return nil
+			}
+			deleteAlertView(g)
+			DetailsList.Focus(g)
+
+			return OpenIssue(g, History.Current(), false)
+		}
+
 		if isDeleteView(v) {
 			projectPath := fmt.Sprintf("%s.%s", ProjectsKey, strings.ToLower(value))
 			if err := config.Set(projectPath, nil); err != nil {
@@ -268,6 +380,82 @@ func SubmitAlert(g *ui.Gui, v *ui.View) error {
 	return nil
 }
 
+// Used when user press Enter to choose an item of the picker
+func SubmitPicker(g *ui.Gui, v *ui.View) error {
+	index := PickerList.CurrentIndex()
+	if index < 0 {
+		return nil
+	}
+
+	g.Update(func(g *ui.Gui) error {
+		if isLinkTypePickerView(v) {
+			selectedLinkChoice = linkChoices[index]
+			deletePickerView(g)
+
+			createPromptView(g, CreateDialogOptions{
+				title:   fmt.Sprintf("%s| %s ", NewLinkTitle, selectedLinkChoice.Label),
+				value:   PickerList.code,
+				suggest: suggestIssueKeys(),
+			})
+
+			return nil
+		}
+
+		if isEditFieldView(v) {
+			fieldValue := parsePickerItems()
+			deletePickerView(g)
+
+			return saveEditingField(g, fieldValue)
+		}
+
+		if isChangelogFilterView(v) {
+			deletePickerView(g)
+
+			return applyChangelogFilter(g, index)
+		}
+
+		if isCopyView(v) {
+			deletePickerView(g)
+
+			return copyChoice(g, index)
+		}
+
+		if isCheckoutBranchView(v) {
+			deletePickerView(g)
+
+			return checkoutBranchChoice(g, index)
+		}
+
+		if isRepoPickerView(v) {
+			deletePickerView(g)
+
+			return chooseRepository(g, index)
+		}
+
+		if isWorktreeView(v) {
+			deletePickerView(g)
+
+			return worktreeChoice(g, index)
+		}
+
+		if isBaseBranchView(v) {
+			deletePickerView(g)
+
+			return chooseBranchBase(g, index)
+		}
+
+		if isDirtyTreeView(v) {
+			deletePickerView(g)
+
+			return chooseUncommittedChanges(g, index)
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
 // Move cursor up on list
 func ListUp(g *ui.Gui, v *ui.View) error {
 	switch v.Name() {
@@ -287,6 +475,21 @@ func ListUp(g *ui.Gui, v *ui.View) error {
 			log.Println("Error on IssuesList.MoveUp()", err)
 			return err
 		}
+	case DetailsView:
+		if err := DetailsList.MoveUp(); err != nil {
+			log.Println("Error on DetailsList.MoveUp()", err)
+			return err
+		}
+	case PickerView:
+		if err := PickerList.MoveUp(); err != nil {
+			log.Println("Error on PickerList.MoveUp()", err)
+			return err
+		}
+	case TimesheetView:
+		if err := TimesheetList.MoveUp(); err != nil {
+			log.Println("Error on TimesheetList.MoveUp()", err)
+			return err
+		}
 	}
 	return nil
 }
@@ -310,6 +513,21 @@ func ListDown(g *ui.Gui, v *ui.View) error {
 			log.Println("Error on IssuesList", err)
 			return err
 		}
+	case DetailsView:
+		if err := DetailsList.MoveDown(); err != nil {
+			log.Println("Error on DetailsList", err)
+			return err
+		}
+	case PickerView:
+		if err := PickerList.MoveDown(); err != nil {
+			log.Println("Error on PickerList", err)
+			return err
+		}
+	case TimesheetView:
+		if err := TimesheetList.MoveDown(); err != nil {
+			log.Println("Error on TimesheetList", err)
+			return err
+		}
 	}
 	return nil
 }
@@ -331,6 +549,11 @@ func ChangeView(g *ui.Gui, v *ui.View) error {
 		return nil
 
 	case IssuesView:
+		IssuesList.Unfocus()
+		DetailsList.Focus(g)
+		return nil
+
+	case DetailsView:
 		if _, err := g.View(ProjectsView); err == nil {
 			ProjectsList.Focus(g)
 		}
@@ -338,7 +561,7 @@ func ChangeView(g *ui.Gui, v *ui.View) error {
 			StatusesList.Focus(g)
 		}
 
-		IssuesList.Unfocus()
+		DetailsList.Unfocus()
 		return nil
 	}
 
@@ -436,7 +659,7 @@ func OnSelectProject(g *ui.Gui, v *ui.View) error {
 			return err
 		}
 
-		IssuesList.SetTitle(" Issues ")
+		IssuesList.SetTitle(makeIssuesTitle())
 
 		return nil
 	})
@@ -472,7 +695,7 @@ func OnEnterProject(g *ui.Gui, v *ui.View) error {
 			return nil
 		}
 
-		IssuesList.SetTitle(" Issues ")
+		IssuesList.SetTitle(makeIssuesTitle())
 		StatusesList.SetTitle(fmt.Sprintf(" Projects > Statuses (%s)", projectCode))
 		ProjectsList.SetTitle(" Projects ")
 
@@ -489,6 +712,10 @@ func RemoveProject(g *ui.Gui, v *ui.View) error {
 	}
 
 	projectCode := currentItem
+	if isBuiltinProject(projectCode) {
+		ShowStatus(g, fmt.Sprintf("%s is built in and can not be deleted", projectCode))
+		return nil
+	}
 
 	ProjectsList.Unfocus()
 
@@ -507,30 +734,205 @@ func RemoveProject(g *ui.Gui, v *ui.View) error {
 
 // Create git branch from selected issue
 func GitBranchPrompt(g *ui.Gui, v *ui.View) error {
-	currentItem := IssuesList.CurrentItem()
-	if currentItem == "" {
+	issueKey := issueKeyFromRow(IssuesList.CurrentItem())
+	issue := findListedIssue(issueKey)
+	if issue == nil {
 		return nil
 	}
 
-	issueName := strings.ReplaceAll(currentItem, " ", "-")
+	projectCode, _, _ := strings.Cut(issueKey, "-")
 
-	prefix := config.String(GitPrefixKey)
+	return selectRepository(g, v, projectCode, func(g *ui.Gui) error {
+		// Offer the branches of the issue first, so they are not created twice
+		if repo, err := openRepository(); err == nil {
+			local, remote, err := findIssueBranches(repo, issueKey)
+			if err == nil && len(local)+len(remote) > 0 {
+				return checkoutBranchPrompt(g, issueKey, append(local, remote...))
+			}
+		}
 
-	branchName := issueName
-	if prefix != "" {
-		branchName = fmt.Sprintf("%s/%s", prefix, issueName)
+		return createBranchPrompt(g, issue)
+	})
+}
+
+// Switch the issues list between flat and epic > story > sub-task tree
+func ToggleTreeMode(g *ui.Gui, v *ui.View) error {
+	if IssuesList.code == "" {
+		return nil
 	}
 
-	createPromptView(g, CreateDialogOptions{
-		title:   NewBranchTitle,
-		content: branchName,
-		value:   branchName,
+	TreeMode = !TreeMode
+
+	IssuesList.SetTitle(" Issues | Fetching... ")
+
+	g.Update(func(g *ui.Gui) error {
+		if err := FetchIssues(g, IssuesList.code); err != nil {
+			IssuesList.SetTitle(" Issues (Error!) ")
+			return nil
+		}
+
+		IssuesList.SetTitle(makeIssuesTitle())
+
+		return nil
 	})
 
-	if err := PromptDialog.SetCursor(len(branchName), 0); err != nil {
+	return nil
+}
+
+func ExpandIssue(g *ui.Gui, v *ui.View) error {
+	if IssuesTree == nil {
+		return nil
+	}
+
+	key := issueKeyFromRow(IssuesList.CurrentItem())
+	if !IssuesTree.SetExpanded(key, true) {
+		return nil
+	}
+
+	return IssuesList.RefreshItems(IssuesTree.Rows())
+}
+
+// Collapse the current row, or its parent when the row has nothing to collapse
+func CollapseIssue(g *ui.Gui, v *ui.View) error {
+	if IssuesTree == nil {
+		return nil
+	}
+
+	key := issueKeyFromRow(IssuesList.CurrentItem())
+	if !IssuesTree.SetExpanded(key, false) {
+		key = IssuesTree.ParentKey(key)
+		if !IssuesTree.SetExpanded(key, false) {
+			return nil
+		}
+	}
+
+	rows := IssuesTree.Rows()
+	if err := IssuesList.RefreshItems(rows); err != nil {
 		return err
 	}
 
+	for index, row := range rows {
+		if issueKeyFromRow(row) == key {
+			return IssuesList.SelectIndex(index)
+		}
+	}
+
+	return nil
+}
+
+// Pressing Enter on an issue will show it in the Details view
+func OnEnterIssue(g *ui.Gui, v *ui.View) error {
+	key := issueKeyFromRow(IssuesList.CurrentItem())
+	if key == "" {
+		return nil
+	}
+
+	IssuesList.Unfocus()
+	DetailsList.Focus(g)
+
+	return OpenIssue(g, key, true)
+}
+
+// Edit what the current line of the Details view refers to
+func OnEditDetailsLine(g *ui.Gui, v *ui.View) error {
+	switch currentDetailsRef().Kind {
+	case FieldRef:
+		return EditDetailsField(g, v)
+	case WorklogRef:
+		return EditWorklog(g, v)
+	}
+
+	return nil
+}
+
+// Remove what the current line of the Details view refers to
+func OnDeleteDetailsLine(g *ui.Gui, v *ui.View) error {
+	switch currentDetailsRef().Kind {
+	case LinkRef:
+		return RemoveIssueLink(g, v)
+	case WorklogRef:
+		return RemoveWorklog(g, v)
+	}
+
+	return nil
+}
+
+// Jump to the issue mentioned on the current line of the Details view
+func OnEnterDetailsLine(g *ui.Gui, v *ui.View) error {
+	if ref := currentDetailsRef(); ref.Kind == DevLinkRef {
+		return OpenInBrowser(g, v)
+	}
+
+	if ref := currentDetailsRef(); ref.Kind == BranchRef {
+		path, name := parseBranchRef(ref.ID)
+		if err := checkoutBranch(g, path, name); err != nil {
+			return err
+		}
+		renderDetails(g)
+		return nil
+	}
+
+	key := issueKeyFromRow(DetailsList.CurrentItem())
+	if key == "" || key == History.Current() {
+		return nil
+	}
+
+	// Free text like the description mentions things looking like keys, e.g.
+	// SHA-256, only the keys of known projects are opened from it
+	if ref := currentDetailsRef(); ref.Kind == FieldRef && !isKnownProjectKey(key) {
+		return nil
+	}
+
+	return OpenIssue(g, key, true)
+}
+
+// The history only moves once the issue is loaded
+func GoBack(g *ui.Gui, v *ui.View) error {
+	key := History.Previous()
+	if key == "" {
+		return nil
+	}
+
+	return loadIssue(g, key, func(string) { History.Back() })
+}
+
+func GoForward(g *ui.Gui, v *ui.View) error {
+	key := History.Next()
+	if key == "" {
+		return nil
+	}
+
+	return loadIssue(g, key, func(string) { History.Forward() })
+}
+
+func NextDetailsTab(g *ui.Gui, v *ui.View) error {
+	return switchDetailsTab(g, 1)
+}
+
+func PrevDetailsTab(g *ui.Gui, v *ui.View) error {
+	return switchDetailsTab(g, len(DetailsTabs)-1)
+}
+
+func switchDetailsTab(g *ui.Gui, step int) error {
+	if CurrentIssue == nil {
+		return nil
+	}
+
+	for index, tab := range DetailsTabs {
+		if tab == CurrentTab {
+			CurrentTab = DetailsTabs[(index+step)%len(DetailsTabs)]
+			break
+		}
+	}
+
+	DetailsList.Reset()
+	DetailsList.SetTitle(fmt.Sprintf(" Details | %s | Fetching... ", CurrentIssue.Key))
+
+	g.Update(func(g *ui.Gui) error {
+		renderDetails(g)
+		return nil
+	})
+
 	return nil
 }
 
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+)
+
+const (
+	OverviewTab    = "Overview"
+	GraphTab       = "Dependencies"
+	WorklogTab     = "Worklog"
+	AttachmentsTab = "Attachments"
+	HistoryTab     = "History"
+	GitTab         = "Git"
+	DevTab         = "Development"
+)
+
+// The tabs of the Details view, in the order they are cycled with [ and ]
+var DetailsTabs = []string{OverviewTab, GraphTab, WorklogTab, AttachmentsTab, HistoryTab, GitTab, DevTab}
+
+var (
+	CurrentIssue *jira.Issue
+	CurrentTab   = OverviewTab
+
+	History = &Navigation{}
+
+	// What each line of the Details view refers to (a field, a link...)
+	detailsRefs []DetailsRef
+)
+
+// Kinds of things a line of the Details view can refer to
+const (
+	FieldRef      = "field"
+	LinkRef       = "link"
+	WorklogRef    = "worklog"
+	AttachmentRef = "attachment"
+	BranchRef     = "branch"
+	CommitRef     = "commit"
+	DevLinkRef    = "devlink"
+)
+
+// DetailsRef tells what a line of the Details view displays, e.g. the
+// "summary" field or the link with id "10001"
+type DetailsRef struct {
+	Kind string
+	ID   string
+}
+
+// DetailsLines are the lines of a Details tab, each line keeps a reference
+// to what it displays so the actions know what to work on
+type DetailsLines struct {
+	lines []string
+	refs  []DetailsRef
+}
+
+// Add appends the lines, all of them referring to ref
+func (d *DetailsLines) Add(ref DetailsRef, lines ...string) {
+	for _, line := range lines {
+		d.lines = append(d.lines, line)
+		d.refs = append(d.refs, ref)
+	}
+}
+
+// AddField appends a "Name: value" line, followed by the error of the last
+// attempt to save the field if any
+func (d *DetailsLines) AddField(field string, name string, value string) {
+	if value == "" {
+		value = "-"
+	}
+
+	d.Add(DetailsRef{FieldRef, field}, withFieldError(field, fmt.Sprintf("%-13s %s", name+":", value)))
+}
+
+// currentDetailsRef returns the reference of the selected line
+func currentDetailsRef() DetailsRef {
+	index := DetailsList.CurrentIndex()
+	if index < 0 || index >= len(detailsRefs) {
+		return DetailsRef{}
+	}
+
+	return detailsRefs[index]
+}
+
+// OpenIssue fetches the issue and displays it in the Details view, the issue is
+// recorded into the navigation history unless we are moving through it
+func OpenIssue(g *ui.Gui, key string, record bool) error {
+	return loadIssue(g, key, func(key string) {
+		if record {
+			History.Visit(key)
+		}
+	})
+}
+
+// loadIssue displays the issue once it is fetched, onLoaded is only called
+// when the fetch succeeds so the history does not point to a missing issue
+func loadIssue(g *ui.Gui, key string, onLoaded func(key string)) error {
+	DetailsList.SetTitle(fmt.Sprintf(" Details | %s | Fetching... ", key))
+
+	g.Update(func(g *ui.Gui) error {
+		issue, err := GetIssueByKey(key)
+		if err != nil {
+			DetailsList.SetTitle(fmt.Sprintf(" Details | Failed to load %s ", key))
+			return nil
+		}
+
+		onLoaded(issue.Key)
+		addRecentIssue(g, issue.Key)
+		forgetTabsData(issue.Key)
+
+		if CurrentIssue == nil || CurrentIssue.Key != issue.Key {
+			fieldErrors = make(map[string]string)
+			changelogFilter = ""
+		}
+
+		CurrentIssue = issue
+		DetailsList.Reset()
+		renderDetails(g)
+
+		return nil
+	})
+
+	return nil
+}
+
+// tabData is what a tab of the Details view fetches in background for an issue
+type tabData struct {
+	seed   int
+	loaded bool
+	value  interface{}
+	err    error
+}
+
+var (
+	// The data of the Details tabs by tab, then by issue key. It is fetched once
+	// per load of the issue, drawing the tab again does not fetch it again
+	tabsData = make(map[string]map[string]*tabData)
+
+	// Bumped on every fetch, the results of a forgotten fetch are dropped
+	tabsSeed int
+)
+
+// getTabData returns the data of the tab for the issue. The first time, it is
+// fetched in background and the tab is drawn again once it is done; loaded is
+// false until then
+func getTabData(g *ui.Gui, tab string, issueKey string, fetch func() (interface{}, error)) (interface{}, bool, error) {
+	if data, ok := tabsData[tab][issueKey]; ok {
+		return data.value, data.loaded, data.err
+	}
+
+	if tabsData[tab] == nil {
+		tabsData[tab] = make(map[string]*tabData)
+	}
+
+	tabsSeed++
+	seed := tabsSeed
+	tabsData[tab][issueKey] = &tabData{seed: seed}
+
+	go func() {
+		value, err := fetch()
+
+		g.Update(func(g *ui.Gui) error {
+			data, ok := tabsData[tab][issueKey]
+			if !ok || data.seed != seed {
+				return nil
+			}
+
+			data.loaded, data.value, data.err = true, value, err
+
+			if CurrentIssue != nil && CurrentIssue.Key == issueKey && CurrentTab == tab {
+				renderDetails(g)
+			}
+
+			return nil
+		})
+	}()
+
+	return nil, false, nil
+}
+
+// forgetTabsData drops what the tabs fetched for the issue, so they show its
+// latest state the next time they are drawn
+func forgetTabsData(issueKey string) {
+	for _, issues := range tabsData {
+		delete(issues, issueKey)
+	}
+}
+
+// renderDetails draws the current tab of the current issue
+func renderDetails(g *ui.Gui) {
+	if CurrentIssue == nil {
+		return
+	}
+
+	var lines *DetailsLines
+	switch CurrentTab {
+	case GraphTab:
+		lines = makeGraphLines(g, CurrentIssue)
+	case WorklogTab:
+		lines = makeWorklogLines(g, CurrentIssue)
+	case AttachmentsTab:
+		lines = makeAttachmentLines(g, CurrentIssue)
+	case HistoryTab:
+		lines = makeChangelogLines(CurrentIssue)
+	case GitTab:
+		lines = makeGitLines(g, CurrentIssue)
+	case DevTab:
+		lines = makeDevStatusLines(g, CurrentIssue)
+	default:
+		lines = makeOverviewLines(CurrentIssue)
+	}
+
+	detailsRefs = lines.refs
+
+	DetailsList.SetTitle(makeDetailsTitle())
+	if err := DetailsList.RefreshItems(lines.lines); err != nil {
+		DetailsList.SetTitle(fmt.Sprintf(" Details | %s (Error!) ", CurrentIssue.Key))
+	}
+}
+
+func makeDetailsTitle() string {
+	tabs := make([]string, len(DetailsTabs))
+	for index, tab := range DetailsTabs {
+		tabs[index] = tab
+		if tab == CurrentTab {
+			tabs[index] = fmt.Sprintf("[%s]", tab)
+		}
+	}
+
+	return fmt.Sprintf(" Details | %s | %s ", CurrentIssue.Key, strings.Join(tabs, " "))
+}
+
+func makeOverviewLines(issue *jira.Issue) *DetailsLines {
+	width := DetailsList.width()
+	fields := issue.Fields
+
+	lines := &DetailsLines{}
+	summary := wrapText(fmt.Sprintf("%s %s", issue.Key, fields.Summary), width)
+	if len(summary) > 0 {
+		summary[0] = withFieldError(SummaryField, summary[0])
+	}
+	lines.Add(DetailsRef{FieldRef, SummaryField}, summary...)
+	lines.Add(DetailsRef{}, "")
+	lines.Add(DetailsRef{}, fmt.Sprintf("Type:         %s", fields.Type.Name))
+	if fields.Status != nil {
+		lines.Add(DetailsRef{}, fmt.Sprintf("Status:       %s", fields.Status.Name))
+	}
+	lines.AddField(PriorityField, "Priority", getPriorityName(fields.Priority))
+	lines.Add(DetailsRef{}, fmt.Sprintf("Assignee:     %s", getUserName(fields.Assignee)))
+	lines.Add(DetailsRef{}, fmt.Sprintf("Reporter:     %s", getUserName(fields.Reporter)))
+	lines.Add(DetailsRef{}, fmt.Sprintf("Watchers:     %s", formatWatchers(issue)))
+	lines.Add(DetailsRef{}, fmt.Sprintf("Votes:        %s", formatVotes(issue)))
+	lines.AddField(LabelsField, "Labels", strings.Join(fields.Labels, ", "))
+	lines.AddField(ComponentsField, "Components", strings.Join(getComponentNames(fields.Components), ", "))
+	lines.AddField(FixVersionsField, "Fix versions", strings.Join(getFixVersionNames(fields.FixVersions), ", "))
+	lines.AddField(DueDateField, "Due date", formatDate(fields.Duedate))
+	lines.AddField(StoryPointsField, "Story points", getStoryPoints(issue))
+	if parentKey := getParentKey(*issue); parentKey != "" {
+		lines.Add(DetailsRef{}, fmt.Sprintf("Parent:       %s", parentKey))
+	}
+
+	if len(fields.IssueLinks) > 0 {
+		lines.Add(DetailsRef{}, "", "Links:")
+		for _, link := range fields.IssueLinks {
+			lines.Add(DetailsRef{LinkRef, link.ID}, fmt.Sprintf("  %s", formatIssueLink(link)))
+		}
+	}
+
+	lines.Add(DetailsRef{}, "")
+	lines.Add(DetailsRef{FieldRef, DescriptionField}, withFieldError(DescriptionField, "Description:"))
+	for _, paragraph := range strings.Split(fields.Description, "\n") {
+		lines.Add(DetailsRef{FieldRef, DescriptionField}, wrapText(paragraph, width)...)
+	}
+
+	return lines
+}
+
+// formatIssueLink describes the link from the point of view of the current issue
+// e.g. "is blocked by ABC-1 Summary [To Do]"
+func formatIssueLink(link *jira.IssueLink) string {
+	direction, other := getLinkedIssue(link)
+
+	return fmt.Sprintf("%s %s", direction, formatLinkedIssue(other))
+}
+
+// getLinkedIssue returns the other side of the link with the matching description
+func getLinkedIssue(link *jira.IssueLink) (string, *jira.Issue) {
+	if link.OutwardIssue != nil {
+		return link.Type.Outward, link.OutwardIssue
+	}
+
+	return link.Type.Inward, link.InwardIssue
+}
+
+func formatLinkedIssue(issue *jira.Issue) string {
+	if issue == nil {
+		return ""
+	}
+	if issue.Fields == nil {
+		return issue.Key
+	}
+
+	status := ""
+	if issue.Fields.Status != nil {
+		status = fmt.Sprintf(" [%s]", issue.Fields.Status.Name)
+	}
+
+	return fmt.Sprintf("%s %s%s", issue.Key, issue.Fields.Summary, status)
+}
+
+func getPriorityName(priority *jira.Priority) string {
+	if priority == nil {
+		return ""
+	}
+
+	return priority.Name
+}
+
+func getComponentNames(components []*jira.Component) []string {
+	names := make([]string, len(components))
+	for index, component := range components {
+		names[index] = component.Name
+	}
+
+	return names
+}
+
+func getFixVersionNames(versions []*jira.FixVersion) []string {
+	names := make([]string, len(versions))
+	for index, version := range versions {
+		names[index] = version.Name
+	}
+
+	return names
+}
+
+func formatDate(date jira.Date) string {
+	if time.Time(date).IsZero() {
+		return ""
+	}
+
+	return time.Time(date).Format(DateLayout)
+}
+
+func getUserName(user *jira.User) string {
+	if user == nil {
+		return "Unassigned"
+	}
+
+	return user.DisplayName
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+)
+
+// The kinds of development information, as named by the dev-status API
+const (
+	PullRequestData = "pullrequest"
+	BranchData      = "branch"
+	RepositoryData  = "repository"
+	BuildData       = "build"
+)
+
+// DevStatusSummary is the response of the dev-status summary, only the
+// applications having information of each kind are kept
+type DevStatusSummary struct {
+	Summary map[string]struct {
+		ByInstanceType map[string]struct {
+			Count int    `json:"count"`
+			Name  string `json:"name"`
+		} `json:"byInstanceType"`
+	} `json:"summary"`
+}
+
+// DevStatusDetailResponse is the response of the dev-status details of one
+// kind, from one application
+type DevStatusDetailResponse struct {
+	Detail []DevStatusDetail `json:"detail"`
+}
+
+// DevStatusDetail is the development information of one application, the
+// fields filled depend on the requested kind
+type DevStatusDetail struct {
+	PullRequests []DevPullRequest `json:"pullRequests"`
+	Branches     []DevBranch      `json:"branches"`
+	Repositories []DevRepository  `json:"repositories"`
+	Builds       []DevBuild       `json:"builds"`
+}
+
+type DevPullRequest struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	URL         string        `json:"url"`
+	Status      string        `json:"status"`
+	Author      DevAuthor     `json:"author"`
+	Reviewers   []DevReviewer `json:"reviewers"`
+	Source      DevBranchRef  `json:"source"`
+	Destination DevBranchRef  `json:"destination"`
+	LastUpdate  string        `json:"lastUpdate"`
+}
+
+type DevAuthor struct {
+	Name string `json:"name"`
+}
+
+type DevReviewer struct {
+	Name     string `json:"name"`
+	Approved bool   `json:"approved"`
+}
+
+type DevBranchRef struct {
+	Branch string `json:"branch"`
+}
+
+type DevBranch struct {
+	Name       string `json:"name"`
+	URL        string `json:"url"`
+	Repository struct {
+		Name string `json:"name"`
+	} `json:"repository"`
+}
+
+type DevRepository struct {
+	Name    string      `json:"name"`
+	URL     string      `json:"url"`
+	Commits []DevCommit `json:"commits"`
+}
+
+type DevCommit struct {
+	DisplayID       string    `json:"displayId"`
+	Message         string    `json:"message"`
+	URL             string    `json:"url"`
+	Author          DevAuthor `json:"author"`
+	AuthorTimestamp string    `json:"authorTimestamp"`
+}
+
+type DevBuild struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	URL         string `json:"url"`
+	State       string `json:"state"`
+	LastUpdated string `json:"lastUpdated"`
+}
+
+// DevStatus gathers the development information of all the applications
+type DevStatus struct {
+	PullRequests []DevPullRequest
+	Branches     []DevBranch
+	Repositories []DevRepository
+	Builds       []DevBuild
+
+	// The applications which failed to answer, e.g. "GitHub build: 500 ..."
+	Failures []string
+}
+
+// devStatusResult is the development information of an issue once fetched, or
+// the error of its summary
+type devStatusResult struct {
+	status *DevStatus
+	err    error
+}
+
+// The development information of the issues, by issue id. A nil result means
+// it is being fetched
+var devStatuses = make(map[string]*devStatusResult)
+
+// DevStatusFetcher fetches the details of one kind from one application
+type DevStatusFetcher func(applicationType string, dataType string) ([]DevStatusDetail, error)
+
+// GetDevStatus asks the summary which applications have information, then
+// fetches the details of each kind from them
+func GetDevStatus(issueID string) (*DevStatus, error) {
+	summary, err := GetDevStatusSummary(issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectDevStatus(summary, func(applicationType string, dataType string) ([]DevStatusDetail, error) {
+		return GetDevStatusDetail(issueID, applicationType, dataType)
+	}), nil
+}
+
+// collectDevStatus gathers the details of the applications listed by the
+// summary, an application failing is skipped so the others are still shown
+func collectDevStatus(summary *DevStatusSummary, fetch DevStatusFetcher) *DevStatus {
+	status := &DevStatus{}
+	for _, dataType := range []string{PullRequestData, BranchData, RepositoryData, BuildData} {
+		for _, applicationType := range summary.applicationTypes(dataType) {
+			details, err := fetch(applicationType, dataType)
+			if err != nil {
+				status.Failures = append(status.Failures, fmt.Sprintf("%s %s: %s", applicationType, dataType, err))
+				continue
+			}
+
+			for _, detail := range details {
+				switch dataType {
+				case PullRequestData:
+					status.PullRequests = append(status.PullRequests, detail.PullRequests...)
+				case BranchData:
+					status.Branches = append(status.Branches, detail.Branches...)
+				case RepositoryData:
+					status.Repositories = append(status.Repositories, detail.Repositories...)
+				case BuildData:
+					status.Builds = append(status.Builds, detail.Builds...)
+				}
+			}
+		}
+	}
+
+	return status
+}
+
+// loadDevStatus fetches the development information in background, the tab
+// is drawn again if it still shows the issue
+func loadDevStatus(g *ui.Gui, issueID string) {
+	devStatuses[issueID] = nil
+
+	go func() {
+		status, err := GetDevStatus(issueID)
+
+		g.Update(func(g *ui.Gui) error {
+			devStatuses[issueID] = &devStatusResult{status, err}
+
+			if CurrentIssue != nil && CurrentIssue.ID == issueID && CurrentTab == DevTab {
+				renderDetails(g)
+			}
+
+			return nil
+		})
+	}()
+}
+
+// applicationTypes lists the applications having information of the kind,
+// in a stable order
+func (s *DevStatusSummary) applicationTypes(dataType string) []string {
+	types := make([]string, 0)
+	for applicationType, instance := range s.Summary[dataType].ByInstanceType {
+		if instance.Count > 0 {
+			types = append(types, applicationType)
+		}
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// makeDevStatusLines is used by the Development tab of the Details view, the
+// lines of the pull requests, branches, commits and builds refer to their URL.
+// The information is fetched once per issue, in background
+func makeDevStatusLines(g *ui.Gui, issue *jira.Issue) *DetailsLines {
+	lines := &DetailsLines{}
+
+	result, ok := devStatuses[issue.ID]
+	if !ok {
+		loadDevStatus(g, issue.ID)
+	}
+	if result == nil {
+		lines.Add(DetailsRef{}, "Loading the development information...")
+		return lines
+	}
+	if result.err != nil {
+		lines.Add(DetailsRef{}, fmt.Sprintf("Failed to load the development information: %s", result.err))
+		return lines
+	}
+
+	status := result.status
+
+	width := DetailsList.width()
+
+	lines.Add(DetailsRef{}, "Pull requests (press o to open):")
+	if len(status.PullRequests) == 0 {
+		lines.Add(DetailsRef{}, "  (none)")
+	}
+	for _, pr := range status.PullRequests {
+		ref := DetailsRef{DevLinkRef, pr.URL}
+
+		line := fmt.Sprintf("  %-8s %s %s (%s → %s)", pr.Status, pr.ID, pr.Name, pr.Source.Branch, pr.Destination.Branch)
+		lines.Add(ref, truncate(line, width))
+
+		details := fmt.Sprintf("           by %s", pr.Author.Name)
+		if updated := parseJiraTime(pr.LastUpdate); !updated.IsZero() {
+			details += fmt.Sprintf(", updated %s", formatRelativeTime(updated))
+		}
+		if len(pr.Reviewers) > 0 {
+			details += fmt.Sprintf(", reviewers: %s", formatReviewers(pr.Reviewers))
+		}
+		lines.Add(ref, truncate(details, width))
+	}
+
+	lines.Add(DetailsRef{}, "", "Branches:")
+	if len(status.Branches) == 0 {
+		lines.Add(DetailsRef{}, "  (none)")
+	}
+	for _, branch := range status.Branches {
+		line := fmt.Sprintf("  %s (%s)", branch.Name, branch.Repository.Name)
+		lines.Add(DetailsRef{DevLinkRef, branch.URL}, truncate(line, width))
+	}
+
+	lines.Add(DetailsRef{}, "", "Commits:")
+	count := 0
+	for _, repository := range status.Repositories {
+		for _, commit := range repository.Commits {
+			title, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+			line := fmt.Sprintf("  %s %s: %s (%s)", commit.DisplayID, commit.Author.Name, title, repository.Name)
+			lines.Add(DetailsRef{DevLinkRef, commit.URL}, truncate(line, width))
+			count++
+		}
+	}
+	if count == 0 {
+		lines.Add(DetailsRef{}, "  (none)")
+	}
+
+	lines.Add(DetailsRef{}, "", "Builds:")
+	if len(status.Builds) == 0 {
+		lines.Add(DetailsRef{}, "  (none)")
+	}
+	for _, build := range status.Builds {
+		name := build.DisplayName
+		if name == "" {
+			name = build.Name
+		}
+
+		line := fmt.Sprintf("  %-10s %s", build.State, name)
+		if updated := parseJiraTime(build.LastUpdated); !updated.IsZero() {
+			line += fmt.Sprintf(", %s", formatRelativeTime(updated))
+		}
+		lines.Add(DetailsRef{DevLinkRef, build.URL}, truncate(line, width))
+	}
+
+	if len(status.Failures) > 0 {
+		lines.Add(DetailsRef{}, "", "Not loaded:")
+		for _, failure := range status.Failures {
+			lines.Add(DetailsRef{}, truncate(fmt.Sprintf("  %s", failure), width))
+		}
+	}
+
+	return lines
+}
+
+// formatReviewers marks the reviewers who approved, e.g. "Bob ✓, Carol"
+func formatReviewers(reviewers []DevReviewer) string {
+	names := make([]string, len(reviewers))
+	for index, reviewer := range reviewers {
+		names[index] = reviewer.Name
+		if reviewer.Approved {
+			names[index] += " ✓"
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
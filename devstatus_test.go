@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// The responses of testdata/devstatus were recorded from Jira Cloud, the
+// Bitbucket application listed by the summary does not answer
+func TestCollectDevStatus(t *testing.T) {
+	summary := &DevStatusSummary{}
+	readDevStatusResponse(t, "summary.json", summary)
+
+	tests := []struct {
+		dataType string
+		want     []string
+	}{
+		{PullRequestData, []string{"GitHub"}},
+		{BranchData, []string{"GitHub", "bitbucket"}},
+		{RepositoryData, []string{"GitHub"}},
+		{BuildData, []string{}},
+	}
+	for _, test := range tests {
+		if got := summary.applicationTypes(test.dataType); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("applicationTypes(%q) = %v, want %v", test.dataType, got, test.want)
+		}
+	}
+
+	status := collectDevStatus(summary, func(applicationType string, dataType string) ([]DevStatusDetail, error) {
+		if applicationType != "GitHub" {
+			return nil, errors.New("503 Service Unavailable")
+		}
+
+		response := &DevStatusDetailResponse{}
+		readDevStatusResponse(t, dataType+".json", response)
+
+		return response.Detail, nil
+	})
+
+	if len(status.PullRequests) != 1 {
+		t.Fatalf("got %d pull requests, want 1", len(status.PullRequests))
+	}
+	pr := status.PullRequests[0]
+	if pr.ID != "#42" || pr.Status != "OPEN" || pr.Source.Branch != "feature/ABC-123-login" || pr.Destination.Branch != "main" {
+		t.Errorf("unexpected pull request %+v", pr)
+	}
+	if got := formatReviewers(pr.Reviewers); got != "Bob ✓, Carol" {
+		t.Errorf("formatReviewers() = %q, want %q", got, "Bob ✓, Carol")
+	}
+	if parseJiraTime(pr.LastUpdate).IsZero() {
+		t.Errorf("could not parse the last update %q", pr.LastUpdate)
+	}
+
+	if len(status.Branches) != 1 || status.Branches[0].Repository.Name != "acme/app" {
+		t.Errorf("unexpected branches %+v", status.Branches)
+	}
+
+	if len(status.Repositories) != 1 || len(status.Repositories[0].Commits) != 1 {
+		t.Fatalf("unexpected repositories %+v", status.Repositories)
+	}
+	if commit := status.Repositories[0].Commits[0]; commit.DisplayID != "4f2d1c9" || commit.Author.Name != "Jane Doe" {
+		t.Errorf("unexpected commit %+v", commit)
+	}
+
+	if len(status.Builds) != 0 {
+		t.Errorf("got %d builds, want none", len(status.Builds))
+	}
+
+	want := []string{"bitbucket branch: 503 Service Unavailable"}
+	if !reflect.DeepEqual(status.Failures, want) {
+		t.Errorf("Failures = %v, want %v", status.Failures, want)
+	}
+}
+
+func readDevStatusResponse(t *testing.T, name string, response interface{}) {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "devstatus", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, response); err != nil {
+		t.Fatal(err)
+	}
+}
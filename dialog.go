@@ -27,6 +27,7 @@ type CreateDialogOptions struct {
 	title   string
 	content string
 	value   string
+	suggest SuggestFunc
 }
 
 // CreateDialog initializes a Dialog object with an existing View by applying some
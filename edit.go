@@ -0,0 +1,405 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+	config "github.com/gookit/config/v2"
+)
+
+// Fields which can be edited from the Details view
+const (
+	SummaryField     = "summary"
+	DescriptionField = "description"
+	PriorityField    = "priority"
+	LabelsField      = "labels"
+	ComponentsField  = "components"
+	FixVersionsField = "fixVersions"
+	DueDateField     = "duedate"
+	// Story points live in a custom field, the real id is found in the config
+	// or in the edit metadata
+	StoryPointsField = "storypoints"
+)
+
+// Kinds of editors, chosen from the schema of the field
+const (
+	TextEditor      = "text"
+	MultilineEditor = "multiline"
+	ListEditor      = "list"
+	DateEditor      = "date"
+	NumberEditor    = "number"
+	SingleEditor    = "single"
+	MultiEditor     = "multi"
+)
+
+// FieldMeta is the edit metadata of a field, as returned by /editmeta
+type FieldMeta struct {
+	Name          string         `json:"name"`
+	Required      bool           `json:"required"`
+	Schema        FieldSchema    `json:"schema"`
+	AllowedValues []AllowedValue `json:"allowedValues"`
+}
+
+type FieldSchema struct {
+	Type   string `json:"type"`
+	Items  string `json:"items"`
+	System string `json:"system"`
+	Custom string `json:"custom"`
+}
+
+// AllowedValue is one of the choices of a field, priorities and components
+// have a name while select lists have a value
+type AllowedValue struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// EditingField is the field being edited, kept between the dialog opening
+// and its submission
+type EditingField struct {
+	IssueKey string
+	Ref      string
+	ID       string
+	Meta     FieldMeta
+}
+
+var (
+	editingField EditingField
+
+	// Errors returned by Jira for the last attempt to save each field
+	fieldErrors = make(map[string]string)
+)
+
+// Open the editor matching the field on the current line of the Details view
+func EditDetailsField(g *ui.Gui, v *ui.View) error {
+	if CurrentIssue == nil {
+		return nil
+	}
+
+	ref := currentDetailsRef()
+	if ref.Kind != FieldRef {
+		return nil
+	}
+
+	issue := CurrentIssue
+
+	g.Update(func(g *ui.Gui) error {
+		metas, err := GetEditMeta(issue.Key)
+		if err != nil {
+			fieldErrors[ref.ID] = err.Error()
+			renderDetails(g)
+			return nil
+		}
+
+		fieldID, meta, ok := findFieldMeta(metas, ref.ID)
+		if !ok {
+			fieldErrors[ref.ID] = "this field can not be edited"
+			renderDetails(g)
+			return nil
+		}
+
+		editingField = EditingField{
+			IssueKey: issue.Key,
+			Ref:      ref.ID,
+			ID:       fieldID,
+			Meta:     meta,
+		}
+
+		DetailsList.Unfocus()
+
+		title := fmt.Sprintf("%s| %s ", EditFieldTitle, meta.Name)
+
+		switch getEditorKind(meta) {
+		case SingleEditor:
+			items := make([]string, len(meta.AllowedValues))
+			for index, allowed := range meta.AllowedValues {
+				items[index] = allowed.Label()
+			}
+			createPickerView(g, CreateDialogOptions{title: title}, items)
+
+		case MultiEditor:
+			selected := make(map[string]bool)
+			for _, name := range getFieldValues(issue, ref.ID) {
+				selected[name] = true
+			}
+
+			items := make([]string, len(meta.AllowedValues))
+			for index, allowed := range meta.AllowedValues {
+				items[index] = fmt.Sprintf("[ ] %s", allowed.Label())
+				if selected[allowed.Label()] {
+					items[index] = fmt.Sprintf("[v] %s", allowed.Label())
+				}
+			}
+			createPickerView(g, CreateDialogOptions{title: title}, items)
+
+		case MultilineEditor:
+			edited, changed, err := EditMarkupInExternalEditor(getFieldText(issue, ref.ID))
+			if err != nil {
+				fieldErrors[ref.ID] = err.Error()
+				DetailsList.Focus(g)
+				renderDetails(g)
+				return nil
+			}
+			if !changed {
+				DetailsList.Focus(g)
+				return nil
+			}
+			return saveEditingField(g, edited)
+
+		default:
+			content := getFieldText(issue, ref.ID)
+			createPromptView(g, CreateDialogOptions{title: title, content: content})
+
+			if err := PromptDialog.SetCursor(len(content), 0); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+// Toggle the current item of a multi-select picker
+func TogglePickerItem(g *ui.Gui, v *ui.View) error {
+	item := PickerList.CurrentItem()
+	if len(item) < 4 || item[0] != '[' {
+		return nil
+	}
+
+	if item[:3] == "[v]" {
+		PickerList.UpdateCurrentItem(fmt.Sprintf("[ ]%s", item[3:]))
+	} else {
+		PickerList.UpdateCurrentItem(fmt.Sprintf("[v]%s", item[3:]))
+	}
+
+	y := PickerList.currentCursorY()
+	if err := PickerList.DrawCurrentPage(); err != nil {
+		return err
+	}
+
+	return PickerList.SetCursor(0, y)
+}
+
+// parseFieldInput converts what was typed in the prompt to the value sent to
+// Jira, a single "-" clears the field
+func parseFieldInput(input string) (interface{}, error) {
+	if input == "-" && getEditorKind(editingField.Meta) != TextEditor {
+		return nil, nil
+	}
+
+	switch getEditorKind(editingField.Meta) {
+	case ListEditor:
+		values := make([]string, 0)
+		for _, value := range strings.Split(input, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		return values, nil
+
+	case DateEditor:
+		if _, err := time.Parse(DateLayout, input); err != nil {
+			return nil, fmt.Errorf("expected a date like %s", DateLayout)
+		}
+		return input, nil
+
+	case NumberEditor:
+		number, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number")
+		}
+		return number, nil
+	}
+
+	return input, nil
+}
+
+// parsePickerItems converts the chosen items of the picker to the value sent
+// to Jira, allowed values are always sent by id
+func parsePickerItems() interface{} {
+	meta := editingField.Meta
+
+	if getEditorKind(meta) == SingleEditor {
+		index := PickerList.CurrentIndex()
+		if index < 0 || index >= len(meta.AllowedValues) {
+			return nil
+		}
+		return map[string]string{"id": meta.AllowedValues[index].ID}
+	}
+
+	values := make([]map[string]string, 0)
+	for index, item := range PickerList.items {
+		if strings.HasPrefix(item, "[v]") && index < len(meta.AllowedValues) {
+			values = append(values, map[string]string{"id": meta.AllowedValues[index].ID})
+		}
+	}
+
+	return values
+}
+
+// saveEditingField sends the new value, errors of Jira are displayed next to
+// the field in the Details view
+func saveEditingField(g *ui.Gui, value interface{}) error {
+	err := UpdateIssueFields(editingField.IssueKey, map[string]interface{}{
+		editingField.ID: value,
+	})
+	if err != nil {
+		fieldErrors[editingField.Ref] = getFieldError(err, editingField.ID)
+	} else {
+		delete(fieldErrors, editingField.Ref)
+	}
+
+	DetailsList.Focus(g)
+
+	return OpenIssue(g, editingField.IssueKey, false)
+}
+
+// getFieldError picks the message about the field in the Jira error response
+func getFieldError(err error, fieldID string) string {
+	var jiraErr *jira.Error
+	if errors.As(err, &jiraErr) {
+		if message, ok := jiraErr.Errors[fieldID]; ok {
+			return message
+		}
+		if len(jiraErr.ErrorMessages) > 0 {
+			return jiraErr.ErrorMessages[0]
+		}
+	}
+
+	return err.Error()
+}
+
+func withFieldError(field string, line string) string {
+	message, ok := fieldErrors[field]
+	if !ok {
+		return line
+	}
+
+	return fmt.Sprintf("%s  (!) %s", line, message)
+}
+
+func getEditorKind(meta FieldMeta) string {
+	switch meta.Schema.Type {
+	case "array":
+		if meta.Schema.Items == "string" && len(meta.AllowedValues) == 0 {
+			return ListEditor
+		}
+		return MultiEditor
+	case "date":
+		return DateEditor
+	case "number":
+		return NumberEditor
+	}
+
+	if isMultilineField(meta) {
+		return MultilineEditor
+	}
+
+	if len(meta.AllowedValues) > 0 {
+		return SingleEditor
+	}
+
+	return TextEditor
+}
+
+// isMultilineField tells whether the field holds a long text, which is
+// edited as Markdown in the external editor
+func isMultilineField(meta FieldMeta) bool {
+	switch meta.Schema.System {
+	case DescriptionField, "environment":
+		return true
+	}
+
+	return strings.HasSuffix(meta.Schema.Custom, ":textarea")
+}
+
+// findFieldMeta returns the metadata of the field, story points are looked
+// up by name when the configured field can not be edited
+func findFieldMeta(metas map[string]FieldMeta, ref string) (string, FieldMeta, bool) {
+	fieldID := ref
+	if ref == StoryPointsField {
+		fieldID = getStoryPointsField()
+	}
+
+	if meta, ok := metas[fieldID]; ok {
+		return fieldID, meta, true
+	}
+
+	if ref == StoryPointsField {
+		for id, meta := range metas {
+			if strings.Contains(strings.ToLower(meta.Name), "story point") {
+				return id, meta, true
+			}
+		}
+	}
+
+	return "", FieldMeta{}, false
+}
+
+// getFieldText returns the current value of a field as typed in the prompt
+func getFieldText(issue *jira.Issue, ref string) string {
+	fields := issue.Fields
+
+	switch ref {
+	case SummaryField:
+		return fields.Summary
+	case DescriptionField:
+		return fields.Description
+	case LabelsField:
+		return strings.Join(fields.Labels, ", ")
+	case DueDateField:
+		return formatDate(fields.Duedate)
+	case StoryPointsField:
+		return getStoryPoints(issue)
+	}
+
+	return ""
+}
+
+// getFieldValues returns the names of the selected values of a multi-select field
+func getFieldValues(issue *jira.Issue, ref string) []string {
+	switch ref {
+	case ComponentsField:
+		return getComponentNames(issue.Fields.Components)
+	case FixVersionsField:
+		return getFixVersionNames(issue.Fields.FixVersions)
+	case LabelsField:
+		return issue.Fields.Labels
+	}
+
+	return nil
+}
+
+func getStoryPointsField() string {
+	return config.String(StoryPointsFieldKey, DefaultStoryPointsField)
+}
+
+func getStoryPoints(issue *jira.Issue) string {
+	value, ok := issue.Fields.Unknowns.Value(getStoryPointsField())
+	if !ok || value == nil {
+		return ""
+	}
+
+	if points, ok := value.(float64); ok {
+		return strconv.FormatFloat(points, 'f', -1, 64)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// Label is the text displayed for the allowed value in the pickers
+func (a AllowedValue) Label() string {
+	if a.Name != "" {
+		return a.Name
+	}
+
+	return a.Value
+}
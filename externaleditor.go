@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+	"github.com/sangdth/lazyjira/markup"
+)
+
+const DefaultEditor = "vi"
+
+// EditInExternalEditor suspends the UI and lets the user edit the text in
+// $VISUAL or $EDITOR, the edited text is returned once the editor exits.
+// It must run in the main loop, e.g. from a keybinding or g.Update
+func EditInExternalEditor(text string) (string, error) {
+	file, err := os.CreateTemp("", "lazyjira-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	args := append(getEditorCommand(), file.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	ui.Suspend()
+	runErr := cmd.Run()
+	if err := ui.Resume(); err != nil {
+		log.Panicln("Error while resuming the UI", err)
+	}
+
+	if runErr != nil {
+		return "", fmt.Errorf("editor %s failed: %w", args[0], runErr)
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(edited), "\n"), nil
+}
+
+// EditMarkupInExternalEditor edits a text stored in the Jira wiki markup as
+// Markdown, the result is converted back to the wiki markup. changed is false
+// when the Markdown is saved as it was written, the text is then returned as is
+func EditMarkupInExternalEditor(text string) (string, bool, error) {
+	original := strings.TrimRight(markup.WikiToMarkdown(text, getMentionName), "\n")
+
+	edited, err := EditInExternalEditor(original)
+	if err != nil {
+		return "", false, err
+	}
+	if edited == original {
+		return text, false, nil
+	}
+
+	return markup.MarkdownToWiki(edited), true, nil
+}
+
+// getMentionName finds the name of a user mentioned in the text, the people of
+// the current issue are known without asking Jira
+func getMentionName(accountID string) string {
+	if CurrentIssue != nil && CurrentIssue.Fields != nil {
+		for _, user := range []*jira.User{CurrentIssue.Fields.Assignee, CurrentIssue.Fields.Reporter, CurrentIssue.Fields.Creator} {
+			if user != nil && user.AccountID == accountID {
+				return user.DisplayName
+			}
+		}
+	}
+
+	user, err := GetUser(accountID)
+	if err != nil {
+		return ""
+	}
+
+	return user.DisplayName
+}
+
+// Edit what is typed in the prompt with the external editor, the prompt is
+// submitted with the edited text
+func EditPromptExternally(g *ui.Gui, v *ui.View) error {
+	edited, err := EditInExternalEditor(v.ViewBuffer())
+	if err != nil {
+		PromptDialog.SetTitles(PromptDialog.Title, fmt.Sprintf(" %s ", err))
+		return nil
+	}
+
+	v.Clear()
+	if _, err := fmt.Fprint(v, edited); err != nil {
+		return err
+	}
+
+	return SubmitPrompt(g, v)
+}
+
+// getEditorCommand splits $VISUAL or $EDITOR, they may contain arguments
+// like "code --wait"
+func getEditorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+
+	return []string{DefaultEditor}
+}
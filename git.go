@@ -0,0 +1,652 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+	git "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	plumbing "github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	config "github.com/gookit/config/v2"
+)
+
+// Finds the issue key in the name of a branch, e.g. "feature/ABC-123-foo" or
+// "feature/abc-123-foo", the key is uppercased. The first group is used when
+// the pattern has one. Keys are not glued to other letters or digits, nor
+// followed by the rest of a version, so "release-2.0" or "lodash-4.17.21" are
+// not taken for keys
+const DefaultIssueKeyPattern = `(?:^|[^A-Za-z0-9])([A-Za-z][A-Za-z0-9_]*-[0-9]+)(?:[^A-Za-z0-9.]|\.[^0-9]|\.?$)`
+
+const (
+	// How many commits of the log are searched for the Git tab
+	GitLogLimit = 1000
+
+	// How many commits mentioning the issue are displayed
+	GitCommitsLimit = 20
+)
+
+// Marks the rows of the issues list having a local branch
+const LocalBranchMarker = "⎇"
+
+const NewBranchChoice = "+ Create a new branch"
+
+var (
+	// The issues having a local branch, by key
+	localBranchKeys = make(map[string]bool)
+
+	// The branches offered by the Check out branch picker, in the order of
+	// its items, and the issue they belong to
+	branchChoices        []plumbing.ReferenceName
+	branchChoiceIssueKey string
+)
+
+// getCurrentBranch returns the short name of the branch checked out in the
+// repository, an error when HEAD is detached
+func getCurrentBranch(repo *git.Repository) (string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	if !head.Name().IsBranch() {
+		return "", errors.New("HEAD is not a branch")
+	}
+
+	return head.Name().Short(), nil
+}
+
+// parseBranchIssueKey extracts the issue key from the branch name with the
+// git.issueKeyPattern regular expression
+func parseBranchIssueKey(branch string) (string, error) {
+	pattern, err := compileIssueKeyPattern()
+	if err != nil {
+		return "", err
+	}
+
+	return findIssueKey(pattern, branch), nil
+}
+
+// compileIssueKeyPattern compiles git.issueKeyPattern, once per search when
+// the names of many branches are read
+func compileIssueKeyPattern() (*regexp.Regexp, error) {
+	pattern, err := regexp.Compile(config.String(IssueKeyPatternKey, DefaultIssueKeyPattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", IssueKeyPatternKey, err)
+	}
+
+	return pattern, nil
+}
+
+// findIssueKey returns the uppercased key found by the pattern, or an empty
+// string
+func findIssueKey(pattern *regexp.Regexp, name string) string {
+	match := pattern.FindStringSubmatch(name)
+	switch {
+	case len(match) == 0:
+		return ""
+	case len(match) > 1 && match[1] != "":
+		return strings.ToUpper(match[1])
+	}
+
+	return strings.ToUpper(match[0])
+}
+
+// focusBranchIssue opens the issue of the branch checked out in the working
+// directory, after selecting its project. Nothing happens outside a repository
+func focusBranchIssue(g *ui.Gui) error {
+	repo, err := openRepositoryAt(".")
+	if err != nil {
+		return nil
+	}
+
+	branch, err := getCurrentBranch(repo)
+	if err != nil {
+		return nil
+	}
+
+	key, err := parseBranchIssueKey(branch)
+	if err != nil {
+		ShowStatus(g, err.Error())
+		return nil
+	}
+	if key == "" {
+		return nil
+	}
+
+	projectCode, _, _ := strings.Cut(key, "-")
+	if index := findItemIndex(ProjectsList, projectCode); index >= 0 {
+		if err := ProjectsList.SelectIndex(index); err != nil {
+			return err
+		}
+
+		IssuesList.SetTitle(" Issues | Fetching... ")
+		if err := FetchIssues(g, ProjectsList.CurrentItem()); err != nil {
+			IssuesList.SetTitle(" Issues (Error!) ")
+		} else {
+			IssuesList.SetTitle(makeIssuesTitle())
+			if index := findIssueRowIndex(key); index >= 0 {
+				if err := IssuesList.SelectIndex(index); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	ProjectsList.Unfocus()
+	DetailsList.Focus(g)
+	ShowStatus(g, fmt.Sprintf("Opening %s, found in the branch %s", key, branch))
+
+	return OpenIssue(g, key, true)
+}
+
+// findItemIndex returns the index of the item, ignoring the case, or -1
+func findItemIndex(list *List, item string) int {
+	for index, current := range list.items {
+		if strings.EqualFold(current, item) {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// findIssueRowIndex returns the index of the row of the issue in the issues
+// list, or -1
+func findIssueRowIndex(key string) int {
+	for index, row := range IssuesList.items {
+		if issueKeyFromRow(row) == key {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// newIssueKeyMatcher matches the texts mentioning the key, like the default
+// git.issueKeyPattern does: ignoring the case, not glued to other letters or
+// digits and not followed by the rest of a version (ABC-1 is neither in
+// ABC-12 nor in ABC-1.2). It is compiled once per key, then run on every
+// commit or worktree
+func newIssueKeyMatcher(key string) *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`(?i)(?:^|[^a-z0-9])%s(?:[^a-z0-9.]|\.[^0-9]|\.?$)`, regexp.QuoteMeta(key)))
+}
+
+// findIssueBranches lists the local and remote branches of the issue, e.g.
+// refs/heads/ABC-1-login and refs/remotes/origin/abc-1-login. The key is
+// read from their name like for the branch flags of the issues list
+func findIssueBranches(repo *git.Repository, key string) ([]plumbing.ReferenceName, []plumbing.ReferenceName, error) {
+	pattern, err := compileIssueKeyPattern()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	local := make([]plumbing.ReferenceName, 0)
+	remote := make([]plumbing.ReferenceName, 0)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		if findIssueKey(pattern, name.Short()) != key {
+			return nil
+		}
+
+		if name.IsBranch() {
+			local = append(local, name)
+		} else if name.IsRemote() && !strings.HasSuffix(name.Short(), "/HEAD") {
+			remote = append(remote, name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sortReferenceNames(local)
+	sortReferenceNames(remote)
+
+	return local, remote, nil
+}
+
+func sortReferenceNames(names []plumbing.ReferenceName) {
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+}
+
+// findIssueCommits returns the most recent commits mentioning the key, the
+// GitLogLimit last commits of every branch are searched
+func findIssueCommits(repo *git.Repository, key string) ([]*object.Commit, error) {
+	iter, err := repo.Log(&git.LogOptions{All: true, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	matcher := newIssueKeyMatcher(key)
+	commits := make([]*object.Commit, 0)
+	scanned := 0
+	err = iter.ForEach(func(commit *object.Commit) error {
+		scanned++
+		if scanned > GitLogLimit || len(commits) >= GitCommitsLimit {
+			return storer.ErrStop
+		}
+
+		if matcher.MatchString(commit.Message) {
+			commits = append(commits, commit)
+		}
+
+		return nil
+	})
+
+	return commits, err
+}
+
+// refreshLocalBranchKeys finds the issues which have a local branch in the
+// repositories of their project, they are flagged in the issues list
+func refreshLocalBranchKeys() {
+	localBranchKeys = make(map[string]bool)
+
+	pattern, err := compileIssueKeyPattern()
+	if err != nil {
+		return
+	}
+
+	for _, path := range getIssuesRepositoryPaths(CurrentIssues) {
+		repo, err := openRepositoryAt(path)
+		if err != nil {
+			continue
+		}
+
+		branches, err := repo.Branches()
+		if err != nil {
+			continue
+		}
+
+		_ = branches.ForEach(func(ref *plumbing.Reference) error {
+			if key := findIssueKey(pattern, ref.Name().Short()); key != "" {
+				localBranchKeys[key] = true
+			}
+			return nil
+		})
+	}
+}
+
+// issueRepository is what the Git tab reads from a repository of the project
+// about an issue. It is read in background as walking the log takes a while
+type issueRepository struct {
+	Path string
+
+	// Why the repository or its branches could not be read, nothing else is
+	// set then
+	Failure string
+
+	Local  []plumbing.ReferenceName
+	Remote []plumbing.ReferenceName
+
+	Worktrees    []Worktree
+	WorktreesErr error
+
+	Commits    []*object.Commit
+	CommitsErr error
+}
+
+// readIssueRepositories reads the branches, worktrees and commits of the issue
+// in every repository of the project
+func readIssueRepositories(paths []string, key string) []issueRepository {
+	repositories := make([]issueRepository, len(paths))
+	for index, path := range paths {
+		repositories[index] = readIssueRepository(path, key)
+	}
+
+	return repositories
+}
+
+func readIssueRepository(path string, key string) issueRepository {
+	data := issueRepository{Path: path}
+
+	repo, err := openRepositoryAt(path)
+	if err != nil {
+		data.Failure = fmt.Sprintf("Failed to open the repository: %s", err)
+		return data
+	}
+
+	data.Local, data.Remote, err = findIssueBranches(repo, key)
+	if err != nil {
+		data.Failure = fmt.Sprintf("Failed to read the branches: %s", err)
+		return data
+	}
+
+	data.Worktrees, data.WorktreesErr = findIssueWorktrees(path, key)
+	data.Commits, data.CommitsErr = findIssueCommits(repo, key)
+
+	return data
+}
+
+// makeGitLines is used by the Git tab of the Details view, the repositories
+// of the project are listed one after the other
+func makeGitLines(g *ui.Gui, issue *jira.Issue) *DetailsLines {
+	lines := &DetailsLines{}
+
+	projectCode, _, _ := strings.Cut(issue.Key, "-")
+	paths := getRepositoryPaths(projectCode)
+	if len(paths) == 0 {
+		lines.Add(DetailsRef{}, fmt.Sprintf("No git repository for %s, add one to %s", projectCode, getReposPath(projectCode)))
+		return lines
+	}
+
+	value, loaded, _ := getTabData(g, GitTab, issue.Key, func() (interface{}, error) {
+		return readIssueRepositories(paths, issue.Key), nil
+	})
+	if !loaded {
+		lines.Add(DetailsRef{}, "Loading git...")
+		return lines
+	}
+
+	repositories := value.([]issueRepository)
+	for index, repository := range repositories {
+		if len(repositories) > 1 {
+			if index > 0 {
+				lines.Add(DetailsRef{}, "")
+			}
+			lines.Add(DetailsRef{}, fmt.Sprintf("── %s (%s)", filepath.Base(repository.Path), repository.Path), "")
+		}
+		addRepositoryLines(lines, repository)
+	}
+
+	return lines
+}
+
+func addRepositoryLines(lines *DetailsLines, repository issueRepository) {
+	if repository.Failure != "" {
+		lines.Add(DetailsRef{}, repository.Failure)
+		return
+	}
+
+	lines.Add(DetailsRef{}, "Local branches (press Enter to check out):")
+	addBranchLines(lines, repository.Path, repository.Local)
+
+	lines.Add(DetailsRef{}, "", "Remote branches:")
+	addBranchLines(lines, repository.Path, repository.Remote)
+
+	lines.Add(DetailsRef{}, "", "Worktrees (press w to manage):")
+	addWorktreeLines(lines, repository.Worktrees, repository.WorktreesErr)
+
+	lines.Add(DetailsRef{}, "", "Commits:")
+	if repository.CommitsErr != nil {
+		lines.Add(DetailsRef{}, fmt.Sprintf("  Failed to read the commits: %s", repository.CommitsErr))
+		return
+	}
+	if len(repository.Commits) == 0 {
+		lines.Add(DetailsRef{}, "  (none)")
+	}
+
+	width := DetailsList.width()
+	for _, commit := range repository.Commits {
+		title, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		line := fmt.Sprintf("  %s %s %s: %s",
+			commit.Hash.String()[:7],
+			commit.Author.When.Local().Format(DateLayout),
+			commit.Author.Name,
+			title,
+		)
+		lines.Add(DetailsRef{CommitRef, commit.Hash.String()}, truncate(line, width))
+	}
+}
+
+// addBranchLines refers to the branches by their full reference name and
+// their repository, e.g. "refs/heads/ABC-1-login:/home/me/app". A ref name
+// can not contain a colon
+func addBranchLines(lines *DetailsLines, path string, branches []plumbing.ReferenceName) {
+	if len(branches) == 0 {
+		lines.Add(DetailsRef{}, "  (none)")
+		return
+	}
+
+	for _, branch := range branches {
+		lines.Add(DetailsRef{BranchRef, fmt.Sprintf("%s:%s", branch, path)}, fmt.Sprintf("  %s", branch.Short()))
+	}
+}
+
+// parseBranchRef reads the ID written by addBranchLines
+func parseBranchRef(id string) (string, plumbing.ReferenceName) {
+	name, path, _ := strings.Cut(id, ":")
+
+	return path, plumbing.ReferenceName(name)
+}
+
+// checkoutBranch checks out the branch given by its full reference name, a
+// remote branch is checked out as a local branch tracking it
+func checkoutBranch(g *ui.Gui, path string, name plumbing.ReferenceName) error {
+	repo, err := openRepositoryAt(path)
+	if err != nil {
+		ShowStatus(g, fmt.Sprintf("Failed to open the repository: %s", err))
+		return nil
+	}
+
+	// go-git moves HEAD before noticing the changes, so they are checked first
+	w, err := repo.Worktree()
+	if err != nil {
+		ShowStatus(g, fmt.Sprintf("Failed to check out %s: %s", name.Short(), err))
+		return nil
+	}
+	if dirty, err := isWorktreeDirty(w); err != nil || dirty {
+		ShowStatus(g, fmt.Sprintf("Commit or stash your changes before checking out %s", name.Short()))
+		return nil
+	}
+
+	branch := name
+	if name.IsRemote() {
+		branch, err = trackRemoteBranch(repo, name)
+		if err != nil {
+			ShowStatus(g, fmt.Sprintf("Failed to check out %s: %s", name.Short(), err))
+			return nil
+		}
+	}
+
+	if err := w.Checkout(&git.CheckoutOptions{Branch: branch}); err != nil {
+		ShowStatus(g, fmt.Sprintf("Failed to check out %s: %s", branch.Short(), err))
+		return nil
+	}
+
+	ShowStatus(g, fmt.Sprintf("Switched to %s", branch.Short()))
+	refreshLocalBranchKeys()
+
+	if CurrentIssue != nil {
+		forgetTabsData(CurrentIssue.Key)
+		if CurrentTab == GitTab {
+			renderDetails(g)
+		}
+	}
+
+	return redrawIssueRows()
+}
+
+// trackRemoteBranch creates the local branch of a remote branch, unless it
+// already exists, e.g. ABC-1-login for origin/ABC-1-login
+func trackRemoteBranch(repo *git.Repository, remote plumbing.ReferenceName) (plumbing.ReferenceName, error) {
+	remoteName, short, _ := strings.Cut(remote.Short(), "/")
+	local := plumbing.NewBranchReferenceName(short)
+
+	if _, err := repo.Reference(local, false); err == nil {
+		return local, nil
+	}
+
+	ref, err := repo.Reference(remote, true)
+	if err != nil {
+		return local, err
+	}
+
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(local, ref.Hash())); err != nil {
+		return local, err
+	}
+
+	err = repo.CreateBranch(&gitconfig.Branch{Name: short, Remote: remoteName, Merge: local})
+
+	return local, err
+}
+
+// checkoutBranchPrompt lets the user choose one of the branches of the issue,
+// or create a new one anyway
+func checkoutBranchPrompt(g *ui.Gui, issueKey string, branches []plumbing.ReferenceName) error {
+	branchChoices = branches
+	branchChoiceIssueKey = issueKey
+
+	items := make([]string, 0, len(branches)+1)
+	for _, branch := range branches {
+		items = append(items, branch.Short())
+	}
+	items = append(items, NewBranchChoice)
+
+	IssuesList.Unfocus()
+
+	createPickerView(g, CreateDialogOptions{title: fmt.Sprintf("%s| %s ", CheckoutBranchTitle, issueKey)}, items)
+
+	return nil
+}
+
+// checkoutBranchChoice is called with the index of the item chosen in the
+// Check out branch picker, the last one creates a new branch
+func checkoutBranchChoice(g *ui.Gui, index int) error {
+	if index < len(branchChoices) {
+		IssuesList.Focus(g)
+		return checkoutBranch(g, gitRepoPath, branchChoices[index])
+	}
+
+	issue := findListedIssue(branchChoiceIssueKey)
+	if issue == nil {
+		IssuesList.Focus(g)
+		return nil
+	}
+
+	return createBranchPrompt(g, issue)
+}
+
+// runGit runs the git binary in the directory, for what go-git does not do
+// (stash, worktrees) or does not do as well (credentials of fetch and push)
+func runGit(dir string, args ...string) error {
+	_, err := runGitOutput(dir, args...)
+
+	return err
+}
+
+// runGitOutput is runGit returning the output, the error carries the message
+// of git when there is one
+func runGitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		message := strings.TrimSpace(string(output))
+		if message == "" {
+			return "", err
+		}
+		return "", errors.New(message)
+	}
+
+	return string(output), nil
+}
+
+// isWorktreeDirty tells whether tracked files have uncommitted changes, the
+// untracked files are not moved by a checkout so they are ignored
+func isWorktreeDirty(w *git.Worktree) (bool, error) {
+	status, err := w.Status()
+	if err != nil {
+		return false, err
+	}
+
+	for _, file := range status {
+		if file.Worktree == git.Untracked {
+			continue
+		}
+		if file.Worktree != git.Unmodified || file.Staging != git.Unmodified {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getDefaultBranch returns the branch new branches usually start from: the
+// git.defaultBranch setting, the HEAD of the remote, then main or master
+func getDefaultBranch(repo *git.Repository) plumbing.ReferenceName {
+	if branch := config.String(DefaultBranchKey); branch != "" {
+		for _, name := range []plumbing.ReferenceName{
+			plumbing.NewRemoteReferenceName(getGitRemote(), branch),
+			plumbing.NewBranchReferenceName(branch),
+		} {
+			if _, err := repo.Reference(name, false); err == nil {
+				return name
+			}
+		}
+	}
+
+	if ref, err := repo.Reference(plumbing.NewRemoteHEADReferenceName(getGitRemote()), false); err == nil {
+		if ref.Type() == plumbing.SymbolicReference {
+			return ref.Target()
+		}
+	}
+
+	for _, branch := range []string{"main", "master"} {
+		for _, name := range []plumbing.ReferenceName{
+			plumbing.NewRemoteReferenceName(getGitRemote(), branch),
+			plumbing.NewBranchReferenceName(branch),
+		} {
+			if _, err := repo.Reference(name, false); err == nil {
+				return name
+			}
+		}
+	}
+
+	return plumbing.HEAD
+}
+
+func getGitRemote() string {
+	return config.String(GitRemoteKey, DefaultGitRemote)
+}
+
+// suggestRefs completes the base of a new branch with the branches and tags
+// of its repository
+func suggestRefs(input string) []Suggestion {
+	if newBranch == nil {
+		return nil
+	}
+
+	repo, err := openRepositoryAt(newBranch.RepoPath)
+	if err != nil {
+		return nil
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil
+	}
+
+	candidates := make([]Suggestion, 0)
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		if name.IsBranch() || name.IsRemote() || name.IsTag() {
+			candidates = append(candidates, Suggestion{Label: name.Short(), Value: name.Short()})
+		}
+		return nil
+	})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Label < candidates[j].Label
+	})
+
+	return filterSuggestions(candidates, input)
+}
@@ -9,6 +9,7 @@ require (
 	github.com/gookit/color v1.5.4
 	github.com/gookit/config/v2 v2.2.4
 	github.com/zalando/go-keyring v0.2.3
+	golang.org/x/text v0.14.0
 )
 
 require (
@@ -54,7 +55,6 @@ require (
 	golang.org/x/sync v0.4.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/term v0.15.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
 	golang.org/x/tools v0.13.0 // indirect
 	golang.org/x/xerrors v0.0.0-20220907171357-04be3eba64a2 // indirect
 	gopkg.in/warnings.v0 v0.1.2 // indirect
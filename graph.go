@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+)
+
+const (
+	BlocksLinkName = "Blocks"
+	GraphMaxDepth  = 5
+)
+
+var graphFields = []string{"summary", "status", "issuelinks"}
+
+// DependencyGraph follows the "Blocks" links level by level, every level is
+// fetched with a single search
+type DependencyGraph struct {
+	issues map[string]*jira.Issue
+}
+
+// BuildDependencyGraph loads the blocking chain around the given issue in both
+// directions, up to GraphMaxDepth levels
+func BuildDependencyGraph(root *jira.Issue) (*DependencyGraph, error) {
+	graph := &DependencyGraph{
+		issues: map[string]*jira.Issue{root.Key: root},
+	}
+
+	level := []*jira.Issue{root}
+	for depth := 0; depth < GraphMaxDepth && len(level) > 0; depth++ {
+		missing := make([]string, 0)
+		for _, issue := range level {
+			for _, key := range append(getBlockerKeys(issue), getBlockedKeys(issue)...) {
+				if _, ok := graph.issues[key]; ok {
+					continue
+				}
+				graph.issues[key] = nil
+				missing = append(missing, key)
+			}
+		}
+
+		if len(missing) == 0 {
+			break
+		}
+
+		fetched, err := SearchIssuesByKeys(missing, graphFields)
+		if err != nil {
+			return nil, err
+		}
+
+		level = make([]*jira.Issue, 0, len(fetched))
+		for index := range fetched {
+			issue := &fetched[index]
+			graph.issues[issue.Key] = issue
+			level = append(level, issue)
+		}
+	}
+
+	return graph, nil
+}
+
+// Lines renders the graph as an ASCII tree, blockers first then blocked issues
+func (d *DependencyGraph) Lines(root *jira.Issue) []string {
+	lines := []string{formatLinkedIssue(root)}
+
+	blockers := getBlockerKeys(root)
+	blocked := getBlockedKeys(root)
+
+	if len(blockers) == 0 && len(blocked) == 0 {
+		return append(lines, "", "No blocking links")
+	}
+
+	visited := map[string]bool{root.Key: true}
+
+	lines = append(lines, "", "Blocked by:")
+	lines = d.appendBranch(lines, blockers, "", visited, getBlockerKeys)
+
+	visited = map[string]bool{root.Key: true}
+
+	lines = append(lines, "", "Blocks:")
+	lines = d.appendBranch(lines, blocked, "", visited, getBlockedKeys)
+
+	return lines
+}
+
+func (d *DependencyGraph) appendBranch(lines []string, keys []string, prefix string, visited map[string]bool, next func(*jira.Issue) []string) []string {
+	if len(keys) == 0 {
+		return append(lines, fmt.Sprintf("%s└── (none)", prefix))
+	}
+
+	for index, key := range keys {
+		connector, childPrefix := "├── ", "│   "
+		if index == len(keys)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+
+		issue := d.issues[key]
+		if issue == nil {
+			lines = append(lines, fmt.Sprintf("%s%s%s", prefix, connector, key))
+			continue
+		}
+
+		if visited[key] {
+			lines = append(lines, fmt.Sprintf("%s%s%s (cycle)", prefix, connector, key))
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("%s%s%s", prefix, connector, formatLinkedIssue(issue)))
+
+		children := next(issue)
+		if len(children) > 0 {
+			visited[key] = true
+			lines = d.appendBranch(lines, children, prefix+childPrefix, visited, next)
+			visited[key] = false
+		}
+	}
+
+	return lines
+}
+
+// getBlockerKeys lists the issues which block the given one
+func getBlockerKeys(issue *jira.Issue) []string {
+	keys := make([]string, 0)
+	for _, link := range getIssueLinks(issue) {
+		if isBlocksLink(link) && link.InwardIssue != nil {
+			keys = append(keys, link.InwardIssue.Key)
+		}
+	}
+
+	return keys
+}
+
+// getBlockedKeys lists the issues which are blocked by the given one
+func getBlockedKeys(issue *jira.Issue) []string {
+	keys := make([]string, 0)
+	for _, link := range getIssueLinks(issue) {
+		if isBlocksLink(link) && link.OutwardIssue != nil {
+			keys = append(keys, link.OutwardIssue.Key)
+		}
+	}
+
+	return keys
+}
+
+func getIssueLinks(issue *jira.Issue) []*jira.IssueLink {
+	if issue == nil || issue.Fields == nil {
+		return nil
+	}
+
+	return issue.Fields.IssueLinks
+}
+
+func isBlocksLink(link *jira.IssueLink) bool {
+	return strings.EqualFold(link.Type.Name, BlocksLinkName)
+}
+
+// makeGraphLines is used by the Dependencies tab of the Details view
+func makeGraphLines(g *ui.Gui, issue *jira.Issue) *DetailsLines {
+	lines := &DetailsLines{}
+
+	value, loaded, err := getTabData(g, GraphTab, issue.Key, func() (interface{}, error) {
+		return BuildDependencyGraph(issue)
+	})
+	if !loaded {
+		lines.Add(DetailsRef{}, "Loading dependencies...")
+		return lines
+	}
+	if err != nil {
+		lines.Add(DetailsRef{}, fmt.Sprintf("Failed to load dependencies: %s", err))
+		return lines
+	}
+
+	lines.Add(DetailsRef{}, value.(*DependencyGraph).Lines(issue)...)
+
+	return lines
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	PrepareCommitMsgHook = "prepare-commit-msg"
+
+	// Written in the hooks installed by lazyjira, so they are told apart from
+	// the hooks of the user
+	HookMarker = "Installed by lazyjira"
+)
+
+// installCommitHook writes the prepare-commit-msg hook of the repository
+// containing the directory. A hook not installed by lazyjira is only
+// replaced with force
+func installCommitHook(dir string, force bool) (string, error) {
+	path, err := getHookPath(dir, PrepareCommitMsgHook)
+	if err != nil {
+		return "", err
+	}
+
+	if content, err := os.ReadFile(path); err == nil && !strings.Contains(string(content), HookMarker) && !force {
+		return path, fmt.Errorf("%s already exists, use --force to replace it", path)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return path, err
+	}
+
+	// A failure of lazyjira must not prevent the commit
+	script := fmt.Sprintf("#!/bin/sh\n# %s, it prefixes the commit messages with the issue key of the branch\n%s hook %s \"$@\" || true\n",
+		HookMarker, shellQuote(executable), PrepareCommitMsgHook)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return path, err
+	}
+
+	return path, os.WriteFile(path, []byte(script), 0o755)
+}
+
+// uninstallCommitHook removes the prepare-commit-msg hook, unless it was not
+// installed by lazyjira
+func uninstallCommitHook(dir string) (string, error) {
+	path, err := getHookPath(dir, PrepareCommitMsgHook)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return path, err
+	}
+	if !strings.Contains(string(content), HookMarker) {
+		return path, fmt.Errorf("%s was not installed by lazyjira", path)
+	}
+
+	return path, os.Remove(path)
+}
+
+// getHookPath asks git where the hook is, core.hooksPath and the worktrees
+// are taken into account
+func getHookPath(dir string, hook string) (string, error) {
+	output, err := runGitOutput(dir, "rev-parse", "--git-path", filepath.Join("hooks", hook))
+	if err != nil {
+		return "", err
+	}
+
+	path := strings.TrimSpace(output)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	return filepath.Abs(path)
+}
+
+// prepareCommitMessage is the prepare-commit-msg hook: the message in the
+// file is prefixed with the issue key of the current branch. The merges,
+// squashes and amended commits are left alone, as are the messages which
+// already mention the key
+func prepareCommitMessage(dir string, file string, source string) error {
+	switch source {
+	case "merge", "squash", "commit":
+		return nil
+	}
+
+	branch, err := runGitOutput(dir, "symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		// Detached HEAD
+		return nil
+	}
+
+	key, err := parseBranchIssueKey(strings.TrimSpace(branch))
+	if err != nil || key == "" {
+		return err
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	message, changed := prefixCommitMessage(string(content), key)
+	if !changed {
+		return nil
+	}
+
+	return os.WriteFile(file, []byte(message), 0o644)
+}
+
+// prefixCommitMessage puts the key in front of the first line of the message
+// which is not a comment, or on a new first line when the message is empty
+func prefixCommitMessage(message string, key string) (string, bool) {
+	lines := strings.Split(message, "\n")
+	matcher := newIssueKeyMatcher(key)
+
+	for index, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if matcher.MatchString(line) {
+			return message, false
+		}
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+
+		lines[index] = fmt.Sprintf("%s %s", key, line)
+		return strings.Join(lines, "\n"), true
+	}
+
+	return fmt.Sprintf("%s \n%s", key, message), true
+}
+
+// shellQuote quotes the text for sh, e.g. it's becomes
+//
+//	'it'\''s'
+func shellQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", `'\''`) + "'"
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	ui "github.com/awesome-gocui/gocui"
+)
+
+// The whole input of the Go to issue prompt must be a key, or only its number
+var (
+	jumpKeyRegexp    = regexp.MustCompile(`^[A-Z][A-Z0-9_]*-[0-9]+$`)
+	jumpNumberRegexp = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// The list focused before the Go to issue prompt was opened
+var jumpReturnList *List
+
+// Ask for the key of any issue to open in the Details view, it does not have
+// to be part of the issues list
+func JumpToIssuePrompt(g *ui.Gui, v *ui.View) error {
+	switch v.Name() {
+	case ProjectsView:
+		jumpReturnList = ProjectsList
+	case StatusesView:
+		jumpReturnList = StatusesList
+	case DetailsView:
+		jumpReturnList = DetailsList
+	default:
+		jumpReturnList = IssuesList
+	}
+	jumpReturnList.Unfocus()
+
+	createPromptView(g, CreateDialogOptions{
+		title:   JumpToIssueTitle,
+		suggest: suggestJumpKeys(),
+	})
+
+	return nil
+}
+
+// submitJumpToIssue opens the issue typed in the prompt, a number alone is
+// looked up in the project of the issues list
+func submitJumpToIssue(g *ui.Gui, input string) error {
+	key := strings.ToUpper(input)
+
+	if jumpNumberRegexp.MatchString(key) && isJumpProject(IssuesList.code) {
+		key = fmt.Sprintf("%s-%s", strings.ToUpper(IssuesList.code), key)
+	}
+
+	if !jumpKeyRegexp.MatchString(key) {
+		PromptDialog.Subtitle = " Expected an issue key like ABC-123 "
+		return nil
+	}
+
+	deletePromptView(g)
+	DetailsList.Focus(g)
+
+	return OpenIssue(g, key, true)
+}
+
+func cancelJumpToIssue(g *ui.Gui) {
+	if jumpReturnList != nil {
+		jumpReturnList.Focus(g)
+	}
+}
+
+// suggestJumpKeys completes the project prefix with the saved projects, then
+// offers the issues already opened or listed
+func suggestJumpKeys() SuggestFunc {
+	projects := make([]string, 0)
+	for _, project := range GetSavedProjects() {
+		if isJumpProject(project) {
+			projects = append(projects, project)
+		}
+	}
+	sort.Strings(projects)
+
+	suggestKeys := suggestIssueKeys()
+
+	return func(input string) []Suggestion {
+		found := make([]Suggestion, 0)
+
+		if !strings.Contains(input, "-") {
+			prefix := strings.ToUpper(strings.TrimSpace(input))
+			for _, project := range projects {
+				if strings.HasPrefix(project, prefix) {
+					found = append(found, Suggestion{Label: project + "-", Value: project + "-"})
+				}
+			}
+		}
+
+		return append(found, suggestKeys(input)...)
+	}
+}
+
+// isJumpProject tells whether the code is a real project, and not one of the
+// entries like "me" which only exist in lazyjira
+func isJumpProject(code string) bool {
+	return code != "" && !strings.EqualFold(code, AssignedToMeKey) && !isBuiltinProject(code)
+}
@@ -36,6 +36,12 @@ func keybindings(g *ui.Gui) error {
 	if err := g.SetKeybinding(ProjectsView, ui.KeyEnter, ui.ModNone, SwitchProjectTab); err != nil {
 		log.Fatal("Failed to set keybindings", err)
 	}
+	if err := g.SetKeybinding(ProjectsView, 'S', ui.ModNone, OpenTimesheet); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(ProjectsView, 'o', ui.ModNone, OpenInBrowser); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
 
 	// STATUSES VIEW
 	if err := g.SetKeybinding(StatusesView, 'b', ui.ModNone, SwitchProjectTab); err != nil {
@@ -53,6 +59,12 @@ func keybindings(g *ui.Gui) error {
 	if err := g.SetKeybinding(StatusesView, ui.KeySpace, ui.ModNone, ToggleStatus); err != nil {
 		log.Fatal("Failed to set keybindings", err)
 	}
+	if err := g.SetKeybinding(StatusesView, 'S', ui.ModNone, OpenTimesheet); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(StatusesView, 'o', ui.ModNone, OpenInBrowser); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
 
 	// PROMPT VIEW
 	if err := g.SetKeybinding(PromptView, ui.KeyEsc, ui.ModNone, CancelDialog); err != nil {
@@ -62,6 +74,20 @@ func keybindings(g *ui.Gui) error {
 		log.Fatal("Failed to set keybindings", err)
 	}
 
+	if err := g.SetKeybinding(PromptView, ui.KeyCtrlE, ui.ModNone, EditPromptExternally); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
+	if err := g.SetKeybinding(PromptView, ui.KeyTab, ui.ModNone, AcceptSuggestion); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(PromptView, ui.KeyArrowDown, ui.ModNone, NextSuggestion); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(PromptView, ui.KeyArrowUp, ui.ModNone, PrevSuggestion); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
 	// ALERT VIEW
 	if err := g.SetKeybinding(AlertView, ui.KeyEsc, ui.ModNone, CancelDialog); err != nil {
 		log.Fatal("Failed to set keybindings", err)
@@ -86,6 +112,208 @@ func keybindings(g *ui.Gui) error {
 	if err := g.SetKeybinding(IssuesView, 'g', ui.ModNone, GitBranchPrompt); err != nil {
 		log.Fatal("Failed to set keybindings", err)
 	}
+	if err := g.SetKeybinding(IssuesView, 'c', ui.ModNone, CommitPrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'w', ui.ModNone, WorktreePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 't', ui.ModNone, ToggleTreeMode); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'l', ui.ModNone, ExpandIssue); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, ui.KeyArrowRight, ui.ModNone, ExpandIssue); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'h', ui.ModNone, CollapseIssue); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, ui.KeyArrowLeft, ui.ModNone, CollapseIssue); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
+	if err := g.SetKeybinding(IssuesView, ui.KeyEnter, ui.ModNone, OnEnterIssue); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'a', ui.ModNone, AssignIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'm', ui.ModNone, AssignToMe); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'u', ui.ModNone, UnassignIssue); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'T', ui.ModNone, ToggleTimer); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'W', ui.ModNone, ToggleWatch); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'V', ui.ModNone, ToggleVote); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'o', ui.ModNone, OpenInBrowser); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'y', ui.ModNone, CopyIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, 'S', ui.ModNone, OpenTimesheet); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
+	// DETAILS VIEW
+	if err := g.SetKeybinding(DetailsView, 'j', ui.ModNone, ListDown); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, ui.KeyArrowDown, ui.ModNone, ListDown); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'k', ui.ModNone, ListUp); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, ui.KeyArrowUp, ui.ModNone, ListUp); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, ui.KeyEnter, ui.ModNone, OnEnterDetailsLine); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'b', ui.ModNone, GoBack); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'f', ui.ModNone, GoForward); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, ']', ui.ModNone, NextDetailsTab); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, '[', ui.ModNone, PrevDetailsTab); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
+	if err := g.SetKeybinding(DetailsView, 'L', ui.ModNone, AddIssueLink); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'd', ui.ModNone, OnDeleteDetailsLine); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
+	if err := g.SetKeybinding(DetailsView, 'e', ui.ModNone, OnEditDetailsLine); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'T', ui.ModNone, ToggleTimer); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'W', ui.ModNone, ToggleWatch); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'V', ui.ModNone, ToggleVote); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'o', ui.ModNone, OpenInBrowser); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'y', ui.ModNone, CopyIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
+	if err := g.SetKeybinding(DetailsView, 's', ui.ModNone, DownloadCurrentAttachment); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'U', ui.ModNone, AttachFilePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
+	if err := g.SetKeybinding(DetailsView, 'c', ui.ModNone, CommitPrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'w', ui.ModNone, WorktreePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, 'F', ui.ModNone, FilterChangelog); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
+	// PICKER VIEW
+	if err := g.SetKeybinding(PickerView, ui.KeyEsc, ui.ModNone, CancelDialog); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(PickerView, ui.KeyEnter, ui.ModNone, SubmitPicker); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(PickerView, ui.KeySpace, ui.ModNone, TogglePickerItem); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(PickerView, 'j', ui.ModNone, ListDown); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(PickerView, ui.KeyArrowDown, ui.ModNone, ListDown); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(PickerView, 'k', ui.ModNone, ListUp); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(PickerView, ui.KeyArrowUp, ui.ModNone, ListUp); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
+	// TIMESHEET VIEW
+	if err := g.SetKeybinding(TimesheetView, ui.KeyEsc, ui.ModNone, CloseTimesheet); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(TimesheetView, 'h', ui.ModNone, PrevTimesheetWeek); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(TimesheetView, ui.KeyArrowLeft, ui.ModNone, PrevTimesheetWeek); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(TimesheetView, 'l', ui.ModNone, NextTimesheetWeek); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(TimesheetView, ui.KeyArrowRight, ui.ModNone, NextTimesheetWeek); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(TimesheetView, 'j', ui.ModNone, ListDown); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(TimesheetView, ui.KeyArrowDown, ui.ModNone, ListDown); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(TimesheetView, 'k', ui.ModNone, ListUp); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(TimesheetView, ui.KeyArrowUp, ui.ModNone, ListUp); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+
+	// JUMP TO ISSUE
+	if err := g.SetKeybinding(ProjectsView, ':', ui.ModNone, JumpToIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(ProjectsView, ui.KeyCtrlG, ui.ModNone, JumpToIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(StatusesView, ':', ui.ModNone, JumpToIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(StatusesView, ui.KeyCtrlG, ui.ModNone, JumpToIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, ':', ui.ModNone, JumpToIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(IssuesView, ui.KeyCtrlG, ui.ModNone, JumpToIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, ':', ui.ModNone, JumpToIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
+	if err := g.SetKeybinding(DetailsView, ui.KeyCtrlG, ui.ModNone, JumpToIssuePrompt); err != nil {
+		log.Fatal("Failed to set keybindings", err)
+	}
 
 	// ALL VIEWS
 	if err := g.SetKeybinding(AllViews, ui.KeyCtrlC, ui.ModNone, Quit); err != nil {
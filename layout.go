@@ -57,6 +57,27 @@ func layout(g *ui.Gui) error {
 		}
 	}
 
+	if _, err := g.View(SuggestionsView); err == nil {
+		_, err := g.SetView(SuggestionsView, tw/6, (th/2)-5, (tw*5)/6, (th/2)+3, 0)
+		if err != nil && err != ui.ErrUnknownView {
+			return err
+		}
+	}
+
+	if _, err := g.View(TimesheetView); err == nil {
+		_, err := g.SetView(TimesheetView, 2, 1, tw-3, th-4, 0)
+		if err != nil && err != ui.ErrUnknownView {
+			return err
+		}
+	}
+
+	if _, err := g.View(PickerView); err == nil {
+		_, err := g.SetView(PickerView, tw/4, (th/2)-10, (tw*3)/4, (th/2)+6, 0)
+		if err != nil && err != ui.ErrUnknownView {
+			return err
+		}
+	}
+
 	if _, err := g.SetView(IssuesView, 0, th-rh+1, rw, th-3, 0); err != nil {
 		log.Panicln("Cannot update view", err)
 	}
@@ -65,5 +86,9 @@ func layout(g *ui.Gui) error {
 		log.Panicln("Cannot update view", err)
 	}
 
+	if err := layoutStatusBar(g); err != nil {
+		log.Panicln("Cannot update view", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+)
+
+// LinkChoice is one direction of a link type, e.g. "is blocked by" of Blocks
+type LinkChoice struct {
+	Label    string
+	TypeName string
+	Outward  bool
+}
+
+var (
+	linkChoices        []LinkChoice
+	selectedLinkChoice LinkChoice
+)
+
+// Open the picker with every link type, then ask for the issue to link to
+func AddIssueLink(g *ui.Gui, v *ui.View) error {
+	if CurrentIssue == nil {
+		return nil
+	}
+
+	issueKey := CurrentIssue.Key
+
+	g.Update(func(g *ui.Gui) error {
+		linkTypes, err := GetIssueLinkTypes()
+		if err != nil {
+			createAlertView(g, CreateDialogOptions{
+				title:   " Alert! ",
+				content: err.Error(),
+			})
+
+			return nil
+		}
+
+		linkChoices = makeLinkChoices(linkTypes)
+
+		items := make([]string, len(linkChoices))
+		for index, choice := range linkChoices {
+			items[index] = fmt.Sprintf("%s (%s)", choice.Label, choice.TypeName)
+		}
+
+		DetailsList.Unfocus()
+		createPickerView(g, CreateDialogOptions{title: LinkTypeTitle, value: issueKey}, items)
+
+		return nil
+	})
+
+	return nil
+}
+
+// Ask for confirmation before removing the link on the current line
+func RemoveIssueLink(g *ui.Gui, v *ui.View) error {
+	if CurrentIssue == nil {
+		return nil
+	}
+
+	ref := currentDetailsRef()
+	if ref.Kind != LinkRef {
+		return nil
+	}
+
+	link := findIssueLinkByID(CurrentIssue, ref.ID)
+	if link == nil {
+		return nil
+	}
+
+	DetailsList.Unfocus()
+
+	createAlertView(g, CreateDialogOptions{
+		title: RemoveLinkTitle,
+		content: fmt.Sprintf(`
+			The link "%s %s" will be removed.
+			Do you want to proceed?`, CurrentIssue.Key, strings.TrimSpace(formatIssueLink(link))),
+		value: link.ID,
+	})
+
+	return nil
+}
+
+func makeLinkChoices(linkTypes []jira.IssueLinkType) []LinkChoice {
+	choices := make([]LinkChoice, 0, len(linkTypes)*2)
+	for _, linkType := range linkTypes {
+		choices = append(choices, LinkChoice{
+			Label:    linkType.Outward,
+			TypeName: linkType.Name,
+			Outward:  true,
+		})
+
+		// e.g. "relates to" reads the same way in both directions
+		if linkType.Inward != linkType.Outward {
+			choices = append(choices, LinkChoice{
+				Label:    linkType.Inward,
+				TypeName: linkType.Name,
+			})
+		}
+	}
+
+	return choices
+}
+
+// The lines of the Links section refer to the id of the link
+func findIssueLinkByID(issue *jira.Issue, linkID string) *jira.IssueLink {
+	for _, link := range getIssueLinks(issue) {
+		if link.ID == linkID {
+			return link
+		}
+	}
+
+	return nil
+}
+
+// suggestIssueKeys proposes the recently opened issues and the issues of the
+// current list. The candidates are collected when the prompt opens, in the
+// main loop, the returned function only filters them
+func suggestIssueKeys() SuggestFunc {
+	summaries := make(map[string]string, len(CurrentIssues))
+	for _, issue := range CurrentIssues {
+		summaries[issue.Key] = formatIssueRow(issue)
+	}
+
+	candidates := make([]Suggestion, 0)
+	seen := make(map[string]bool)
+	for _, key := range History.Keys() {
+		label := key
+		if summary, ok := summaries[key]; ok {
+			label = summary
+		}
+		candidates = append(candidates, Suggestion{Label: label, Value: key})
+		seen[key] = true
+	}
+
+	for _, issue := range CurrentIssues {
+		if seen[issue.Key] {
+			continue
+		}
+		candidates = append(candidates, Suggestion{Label: formatIssueRow(issue), Value: issue.Key})
+	}
+
+	return func(input string) []Suggestion {
+		return filterSuggestions(candidates, input)
+	}
+}
@@ -63,6 +63,7 @@ func (l *List) Unfocus() {
 func (l *List) Reset() {
 	l.items = make([]string, 0)
 	l.pages = []Page{}
+	l.pageIndex = 0
 	l.Clear()
 	l.ResetCursor()
 }
@@ -99,6 +100,29 @@ func (l *List) SetItems(data []string) {
 	}
 }
 
+// RefreshItems replaces the items but stays on the current page and row, used
+// when the list content changes in place (e.g. expanding a tree row)
+func (l *List) RefreshItems(data []string) error {
+	l.items = data
+	l.ResetPages()
+	if l.IsEmpty() {
+		l.Clear()
+		return nil
+	}
+	if l.pageIndex >= l.pagesNum() {
+		l.pageIndex = l.pagesNum() - 1
+	}
+	y := l.currentCursorY()
+	if err := l.DrawCurrentPage(); err != nil {
+		return err
+	}
+	if y >= l.currPage().limit {
+		y = l.currPage().limit - 1
+	}
+
+	return l.SetCursor(0, y)
+}
+
 // AddItem appends a given item to the existing list
 func (l *List) AddItem(g *ui.Gui, item string) {
 	l.items = append(l.items, item)
@@ -211,6 +235,32 @@ func (l *List) CurrentItem() string {
 	return data[l.currentCursorY()]
 }
 
+// CurrentIndex returns the index of the selected item in the whole list
+func (l *List) CurrentIndex() int {
+	if l.IsEmpty() {
+		return -1
+	}
+
+	return l.currPage().offset + l.currentCursorY()
+}
+
+// SelectIndex shows the page of the item with index i and puts the cursor on it
+func (l *List) SelectIndex(i int) error {
+	if i < 0 || i >= l.length() {
+		return nil
+	}
+	for index, page := range l.pages {
+		if i >= page.offset && i < page.offset+page.limit {
+			if err := l.displayPage(index); err != nil {
+				return err
+			}
+			return l.SetCursor(0, i-page.offset)
+		}
+	}
+
+	return nil
+}
+
 // ResetCursor puts the cirson back at the beginning of the View
 func (l *List) ResetCursor() {
 	err := l.SetCursor(0, 0)
@@ -2,7 +2,9 @@ package main
 
 import (
 	"log"
+	"os"
 
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
 	ui "github.com/awesome-gocui/gocui"
 )
 
@@ -14,6 +16,11 @@ const (
 	DetailsView  = "details"
 	PromptView   = "prompt"
 	AlertView    = "alert"
+	PickerView   = "picker"
+
+	SuggestionsView = "suggestions"
+	StatusBarView   = "statusbar"
+	TimesheetView   = "timesheet"
 )
 
 var (
@@ -21,15 +28,29 @@ var (
 	StatusesList *List
 	IssuesList   *List
 
-	Details *ui.View
+	CurrentIssues []jira.Issue
+	IssuesTree    *IssueTree
+	TreeMode      bool
+
+	DetailsList *List
 
 	PromptDialog *Dialog
 	AlertDialog  *Dialog
+	PickerList   *List
 )
 
 func main() {
+	// The commands set up the config themselves, the commit hook must not
+	// stop git when there is none
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:]))
+	}
+
 	initConfigSetup()
 
+	loadTimer()
+	loadRecentIssues()
+
 	// Initialize the gocui library
 	g, err := ui.NewGui(ui.OutputNormal, true)
 	if err != nil {
@@ -60,7 +81,7 @@ func main() {
 
 	g.Update(func(g *ui.Gui) error {
 		loadProjects()
-		return nil
+		return focusBranchIssue(g)
 	})
 
 	v, err = g.SetView(IssuesView, 0, th-rh+1, rw, th-3, 0)
@@ -70,12 +91,14 @@ func main() {
 	IssuesList = CreateList(v, false)
 	IssuesList.Title = " Issues "
 
-	Details, err = g.SetView(DetailsView, rw+1, 0, tw-1, th-3, 0)
+	v, err = g.SetView(DetailsView, rw+1, 0, tw-1, th-3, 0)
 	if err != nil && err != ui.ErrUnknownView {
 		log.Panicln("Failed to create Details view", err)
 	}
-	Details.Title = " Details "
-	Details.Wrap = true
+	DetailsList = CreateList(v, false)
+	DetailsList.Title = " Details "
+
+	startStatusBarTicker(g)
 
 	// Start the main event loop
 	if err := g.MainLoop(); err != nil && err != ui.ErrQuit {
@@ -0,0 +1,171 @@
+package markup
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ADFVersion is the version of the Atlassian Document Format written by RenderADF
+const ADFVersion = 1
+
+// ParseADF reads a document in the Atlassian Document Format, as returned by
+// the REST API v3 of Jira Cloud
+func ParseADF(data []byte) (*Node, error) {
+	doc := &Node{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+
+	if doc.Type != DocNode {
+		return nil, fmt.Errorf("expected a %q node at the root of the document, got %q", DocNode, doc.Type)
+	}
+
+	return doc, nil
+}
+
+// RenderADF writes the document in the Atlassian Document Format. The tree is
+// first adjusted to the rules of the format, e.g. empty text nodes are dropped
+// and task items get an id
+func RenderADF(doc *Node) ([]byte, error) {
+	ids := 0
+	normalized := normalizeADF(doc, &ids)
+	normalized.Version = ADFVersion
+
+	// The content of the document is required, even when it is empty
+	if len(normalized.Content) == 0 {
+		return json.Marshal(map[string]interface{}{
+			"type":    DocNode,
+			"version": ADFVersion,
+			"content": []*Node{},
+		})
+	}
+
+	return json.Marshal(normalized)
+}
+
+// normalizeADF copies the node, fixing what the format does not allow
+func normalizeADF(n *Node, ids *int) *Node {
+	normalized := &Node{
+		Type:  n.Type,
+		Text:  n.Text,
+		Marks: normalizeADFMarks(n.Marks),
+	}
+
+	if len(n.Attrs) > 0 {
+		normalized.Attrs = make(map[string]interface{}, len(n.Attrs))
+		for key, value := range n.Attrs {
+			normalized.Attrs[key] = value
+		}
+	}
+
+	for _, child := range n.Content {
+		switch {
+		case child.Type == TextNode && child.Text == "":
+			continue
+
+		// The macros of the wiki markup stay as text around their blocks
+		case child.Type == WikiMacroNode:
+			opening, closing := macroTags(child)
+			normalized.Content = append(normalized.Content, newBlock(ParagraphNode, newText(opening, nil)))
+			for _, block := range child.Content {
+				normalized.Content = append(normalized.Content, normalizeADF(block, ids))
+			}
+			normalized.Content = append(normalized.Content, newBlock(ParagraphNode, newText(closing, nil)))
+			continue
+		}
+
+		// The text split by the parsers, e.g. around the escaped characters,
+		// is joined back
+		child := normalizeADF(child, ids)
+		if count := len(normalized.Content); count > 0 && child.Type == TextNode {
+			if last := normalized.Content[count-1]; last.Type == TextNode && reflect.DeepEqual(last.Marks, child.Marks) {
+				last.Text += child.Text
+				continue
+			}
+		}
+		normalized.Content = append(normalized.Content, child)
+	}
+
+	switch n.Type {
+	case WikiMarkupNode:
+		return newText(n.Attr("markup"), normalized.Marks)
+
+	case TextNode, HardBreakNode:
+		// Escapes and explicit breaks only matter to the markups
+		normalized.Attrs = nil
+
+	case CodeBlockNode:
+		delete(normalized.Attrs, "macro")
+		delete(normalized.Attrs, "params")
+		if len(normalized.Attrs) == 0 {
+			normalized.Attrs = nil
+		}
+
+	case TaskListNode, TaskItemNode:
+		if normalized.Attr("localId") == "" {
+			*ids++
+			if normalized.Attrs == nil {
+				normalized.Attrs = make(map[string]interface{})
+			}
+			normalized.Attrs["localId"] = fmt.Sprintf("task-%d", *ids)
+		}
+
+	case ListItemNode, TableHeaderNode, TableCellNode:
+		// These nodes must hold at least one block
+		if len(normalized.Content) == 0 {
+			normalized.Content = []*Node{newBlock(ParagraphNode)}
+		}
+
+	case MentionNode:
+		// Mentions written by hand only know the name of the user
+		delete(normalized.Attrs, "username")
+	}
+
+	return normalized
+}
+
+// normalizeADFMarks keeps only the link next to a code mark, code can not be
+// combined with the other formattings
+func normalizeADFMarks(marks []Mark) []Mark {
+	if len(marks) == 0 {
+		return nil
+	}
+
+	code := false
+	for _, mark := range marks {
+		if mark.Type == CodeMark {
+			code = true
+		}
+	}
+
+	normalized := make([]Mark, 0, len(marks))
+	for _, mark := range marks {
+		if code && mark.Type != CodeMark && mark.Type != LinkMark {
+			continue
+		}
+		normalized = append(normalized, mark)
+	}
+
+	return normalized
+}
+
+// ADFToMarkdown converts a text stored by Jira Cloud so it can be edited as Markdown
+func ADFToMarkdown(data []byte) (string, error) {
+	if len(strings.TrimSpace(string(data))) == 0 || string(data) == "null" {
+		return "", nil
+	}
+
+	doc, err := ParseADF(data)
+	if err != nil {
+		return "", err
+	}
+
+	return RenderMarkdown(doc), nil
+}
+
+// MarkdownToADF converts an edited Markdown text back to the Atlassian Document Format
+func MarkdownToADF(text string) ([]byte, error) {
+	return RenderADF(ParseMarkdown(text))
+}
@@ -0,0 +1,987 @@
+package markup
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	mdHeadingRegexp  = regexp.MustCompile(`^(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	mdRuleRegexp     = regexp.MustCompile(`^(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	mdListItemRegexp = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])(?:\s+(.*))?$`)
+	mdFenceRegexp    = regexp.MustCompile("^(\\s*)(`{3,}|~{3,})\\s*([^`]*)$")
+	mdOrderedRegexp  = regexp.MustCompile(`^(\d+)([.)])(\s)`)
+	mdTaskRegexp     = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	mdDelimRowRegexp = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	mdBreakRegexp    = regexp.MustCompile(`^<br\s*/?>`)
+
+	// The marker of a task, once escaped by Markdown or by the wiki markup
+	escapedTaskRegexp = regexp.MustCompile(`^\\\[([ xX])\\?\] `)
+)
+
+// Links to these schemes are mentions, e.g. [@John Doe](accountid:5b10ac8d82e05b22cc7d4ef5)
+// for Jira Cloud or [@jdoe](user:jdoe) for Jira Server
+const (
+	mentionScheme  = "accountid:"
+	usernameScheme = "user:"
+)
+
+// ParseMarkdown reads a CommonMark document with the GitHub additions
+// (fenced code, strike through). Line breaks inside a paragraph are kept as
+// hard breaks, like Jira does when rendering a comment. The links, macros and
+// images of the wiki markup, which Markdown can not write, are read as they are.
+func ParseMarkdown(text string) *Node {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+
+	return NewDoc().Append(parseMarkdownBlocks(lines)...)
+}
+
+func parseMarkdownBlocks(lines []string) []*Node {
+	blocks := make([]*Node, 0)
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case isIndentedCode(line):
+			var block *Node
+			block, i = parseMarkdownIndentedCode(lines, i)
+			blocks = append(blocks, block)
+
+		case mdFenceRegexp.MatchString(line):
+			var block *Node
+			block, i = parseMarkdownFence(lines, i)
+			blocks = append(blocks, block)
+
+		case mdHeadingRegexp.MatchString(trimmed):
+			match := mdHeadingRegexp.FindStringSubmatch(trimmed)
+			blocks = append(blocks, newHeading(len(match[1]), parseMarkdownInline(match[2], nil)...))
+			i++
+
+		case mdRuleRegexp.MatchString(trimmed):
+			blocks = append(blocks, newBlock(RuleNode))
+			i++
+
+		case strings.HasPrefix(trimmed, ">"):
+			quoted := make([]string, 0)
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				quote := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(quote, " "))
+				i++
+			}
+			blocks = append(blocks, newBlock(BlockquoteNode, parseMarkdownBlocks(quoted)...))
+
+		case mdListItemRegexp.MatchString(line):
+			var list *Node
+			list, i = parseMarkdownList(lines, i)
+			blocks = append(blocks, list)
+
+		case isMarkdownTable(lines, i):
+			var table *Node
+			table, i = parseMarkdownTable(lines, i)
+			blocks = append(blocks, table)
+
+		case findMacroBlock(lines, i) > i:
+			var block *Node
+			block, i = parseMacroBlock(lines, i, parseMarkdownBlocks)
+			blocks = append(blocks, block)
+
+		default:
+			paragraph := make([]string, 0)
+			for i < len(lines) && (len(paragraph) == 0 || !startsMarkdownBlock(lines[i])) {
+				paragraph = append(paragraph, strings.TrimSpace(lines[i]))
+				i++
+			}
+			blocks = append(blocks, newBlock(ParagraphNode, parseMarkdownInline(strings.Join(paragraph, "\n"), nil)...))
+		}
+	}
+
+	return blocks
+}
+
+// startsMarkdownBlock tells whether the line ends the paragraph before it
+func startsMarkdownBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+
+	return trimmed == "" ||
+		mdFenceRegexp.MatchString(line) ||
+		mdHeadingRegexp.MatchString(trimmed) ||
+		mdRuleRegexp.MatchString(trimmed) ||
+		strings.HasPrefix(trimmed, ">") ||
+		mdListItemRegexp.MatchString(line)
+}
+
+// isMarkdownTable tells whether a table starts at the line, a table has a
+// header row followed by the delimiter row, e.g. "| --- | :-: |"
+func isMarkdownTable(lines []string, i int) bool {
+	return i+1 < len(lines) &&
+		strings.Contains(lines[i], "|") &&
+		strings.Contains(lines[i+1], "-") &&
+		mdDelimRowRegexp.MatchString(strings.TrimSpace(lines[i+1]))
+}
+
+func parseMarkdownTable(lines []string, start int) (*Node, int) {
+	table := newBlock(TableNode)
+
+	// An empty header row stands for a table without header
+	header := parseMarkdownRow(lines[start])
+	for _, cell := range header {
+		if len(cell) > 0 {
+			table.Append(newTableRow(true, header))
+			break
+		}
+	}
+
+	i := start + 2
+	for ; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" || !strings.Contains(lines[i], "|") {
+			break
+		}
+		table.Append(newTableRow(false, parseMarkdownRow(lines[i])))
+	}
+
+	return table, i
+}
+
+// parseMarkdownRow splits the row on the pipes which are not escaped
+func parseMarkdownRow(line string) [][]*Node {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+
+	cells := make([][]*Node, 0)
+	start := 0
+	for i := 0; i <= len(line); i++ {
+		if i < len(line) && line[i] == '\\' {
+			i++
+			continue
+		}
+		if i == len(line) || line[i] == '|' {
+			// Escaped pipes are part of the cell, even inside code spans
+			cell := strings.ReplaceAll(strings.TrimSpace(line[start:i]), "\\|", "|")
+			cells = append(cells, parseMarkdownInline(cell, nil))
+			start = i + 1
+		}
+	}
+
+	return cells
+}
+
+// isIndentedCode tells whether the line is part of a code block indented by
+// four spaces or a tab. Such lines can not interrupt a paragraph, so they are
+// only checked at the start of a block
+func isIndentedCode(line string) bool {
+	return strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+}
+
+// parseMarkdownIndentedCode reads the indented lines, blank lines between them
+// are part of the code
+func parseMarkdownIndentedCode(lines []string, start int) (*Node, int) {
+	code := make([]string, 0)
+
+	i := start
+	for i < len(lines) {
+		if strings.TrimSpace(lines[i]) == "" {
+			next := nextNonBlank(lines, i)
+			if next < 0 || !isIndentedCode(lines[next]) {
+				break
+			}
+			for ; i < next; i++ {
+				code = append(code, "")
+			}
+			continue
+		}
+		if !isIndentedCode(lines[i]) {
+			break
+		}
+
+		if strings.HasPrefix(lines[i], "\t") {
+			code = append(code, lines[i][1:])
+		} else {
+			code = append(code, lines[i][4:])
+		}
+		i++
+	}
+
+	return newCodeBlock("", strings.Join(code, "\n")), i
+}
+
+func parseMarkdownFence(lines []string, start int) (*Node, int) {
+	match := mdFenceRegexp.FindStringSubmatch(lines[start])
+	indent, fence, info := len(match[1]), match[2], strings.TrimSpace(match[3])
+
+	code := make([]string, 0)
+	i := start + 1
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+			i++
+			break
+		}
+		code = append(code, dedent(lines[i], indent))
+	}
+
+	return newFencedCode(info, strings.Join(code, "\n")), i
+}
+
+// newFencedCode reads the info string of the fence. Besides the language, it
+// keeps the parameters of the wiki macro, e.g. "noformat" or "title=Foo.java|java"
+func newFencedCode(info string, code string) *Node {
+	if macro, params, _ := strings.Cut(info, ":"); macro == "noformat" {
+		return newWikiCode(macro, params, code)
+	}
+	if strings.ContainsAny(info, "=|") {
+		return newWikiCode("code", info, code)
+	}
+
+	language, _, _ := strings.Cut(info, " ")
+
+	return newCodeBlock(language, code)
+}
+
+// markdownCodeInfo writes the info string read by newFencedCode
+func markdownCodeInfo(n *Node) string {
+	macro, params := wikiCodeMacro(n)
+	if macro == "noformat" && params != "" {
+		return macro + ":" + params
+	}
+	if macro == "noformat" {
+		return macro
+	}
+
+	return params
+}
+
+// parseMarkdownList reads the items of a list, the lines of an item are the
+// ones indented deeper than its marker
+func parseMarkdownList(lines []string, start int) (*Node, int) {
+	first := mdListItemRegexp.FindStringSubmatch(lines[start])
+	indent := len(first[1])
+	ordered := isOrderedMarker(first[2])
+
+	list := newBlock(BulletListNode)
+	if ordered {
+		list.Type = OrderedListNode
+		if order, _ := strconv.Atoi(strings.TrimRight(first[2], ".)")); order > 1 {
+			list.Attrs = map[string]interface{}{"order": order}
+		}
+	}
+
+	// The state of each task item, or an empty string for the usual items
+	tasks := make([]string, 0)
+
+	i := start
+	for i < len(lines) {
+		match := mdListItemRegexp.FindStringSubmatch(lines[i])
+		if match == nil || len(match[1]) != indent || isOrderedMarker(match[2]) != ordered {
+			break
+		}
+
+		contentIndent := len(match[1]) + len(match[2]) + 1
+		itemLines := []string{match[3]}
+		i++
+
+		task := ""
+		if !ordered {
+			if marker := mdTaskRegexp.FindStringSubmatch(match[3]); marker != nil {
+				task = marker[1]
+				itemLines[0] = match[3][len(marker[0]):]
+			}
+		}
+		tasks = append(tasks, task)
+
+		for i < len(lines) {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				// A blank line belongs to the item if the item goes on after it
+				next := nextNonBlank(lines, i)
+				if next < 0 || indentOf(lines[next]) < contentIndent {
+					break
+				}
+				itemLines = append(itemLines, "")
+				i++
+				continue
+			}
+
+			if indentOf(line) > indent {
+				itemLines = append(itemLines, dedent(line, contentIndent))
+				i++
+				continue
+			}
+
+			// Lazy continuation of the first paragraph of the item
+			if !startsMarkdownBlock(line) && itemLines[len(itemLines)-1] != "" {
+				itemLines = append(itemLines, strings.TrimSpace(line))
+				i++
+				continue
+			}
+
+			break
+		}
+
+		list.Append(newBlock(ListItemNode, parseMarkdownBlocks(itemLines)...))
+
+		// Items separated by blank lines still make a single list
+		if next := nextNonBlank(lines, i); next > i {
+			if match := mdListItemRegexp.FindStringSubmatch(lines[next]); match != nil &&
+				len(match[1]) == indent && isOrderedMarker(match[2]) == ordered {
+				i = next
+			}
+		}
+	}
+
+	return toTaskList(list, tasks), i
+}
+
+// toTaskList turns the list into a task list when all its items are tasks
+// made of a single paragraph, maybe followed by nested task lists. Other
+// lists keep the markers of their tasks as text
+func toTaskList(list *Node, tasks []string) *Node {
+	convertible := true
+	for index, item := range list.Content {
+		if tasks[index] == "" || len(item.Content) == 0 || item.Content[0].Type != ParagraphNode {
+			convertible = false
+			break
+		}
+		for _, block := range item.Content[1:] {
+			if block.Type != TaskListNode {
+				convertible = false
+			}
+		}
+	}
+
+	if !convertible {
+		for index, item := range list.Content {
+			if tasks[index] == "" {
+				continue
+			}
+			marker := newText(fmt.Sprintf("[%s] ", tasks[index]), nil)
+			if len(item.Content) > 0 && item.Content[0].Type == ParagraphNode {
+				item.Content[0].Content = append([]*Node{marker}, item.Content[0].Content...)
+			} else {
+				item.Content = append([]*Node{newBlock(ParagraphNode, marker)}, item.Content...)
+			}
+		}
+		return list
+	}
+
+	taskList := newBlock(TaskListNode)
+	for index, item := range list.Content {
+		taskList.Append(newTaskItem(tasks[index] != " ", item.Content[0].Content...))
+		taskList.Append(item.Content[1:]...)
+	}
+
+	return taskList
+}
+
+func isOrderedMarker(marker string) bool {
+	return marker[0] >= '0' && marker[0] <= '9'
+}
+
+func nextNonBlank(lines []string, start int) int {
+	for i := start; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != "" {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// dedent removes up to n spaces at the beginning of the line
+func dedent(line string, n int) string {
+	indent := indentOf(line)
+	if indent > n {
+		indent = n
+	}
+
+	return line[indent:]
+}
+
+// parseMarkdownInline reads the text of a block, every text node gets the
+// given marks in addition to its own
+func parseMarkdownInline(text string, marks []Mark) []*Node {
+	nodes := make([]*Node, 0)
+
+	var buffer strings.Builder
+	flush := func() {
+		if buffer.Len() > 0 {
+			nodes = append(nodes, newText(buffer.String(), marks))
+			buffer.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		rest := text[i:]
+
+		switch {
+		case c == '\\' && i+1 < len(text) && isPunct(text[i+1]):
+			flush()
+			nodes = append(nodes, newEscaped(text[i+1], marks))
+			i += 2
+			continue
+
+		case c == '\n':
+			flush()
+			nodes = append(nodes, newBreak(false))
+			i++
+			continue
+
+		case c == '`':
+			ticks := len(rest) - len(strings.TrimLeft(rest, "`"))
+			fence := rest[:ticks]
+			if end := strings.Index(rest[ticks:], fence); end >= 0 {
+				code := rest[ticks : ticks+end]
+				if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				flush()
+				nodes = append(nodes, newText(code, withMark(marks, Mark{Type: CodeMark})))
+				i += ticks*2 + end
+				continue
+			}
+			buffer.WriteString(fence)
+			i += ticks
+			continue
+
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			if inner, length, ok := findMarkdownDelimited(text, i, rest[:2]); ok {
+				flush()
+				nodes = append(nodes, parseMarkdownInline(inner, withMark(marks, Mark{Type: StrongMark}))...)
+				i += length
+				continue
+			}
+
+		case strings.HasPrefix(rest, "~~"):
+			if inner, length, ok := findMarkdownDelimited(text, i, "~~"); ok {
+				flush()
+				nodes = append(nodes, parseMarkdownInline(inner, withMark(marks, Mark{Type: StrikeMark}))...)
+				i += length
+				continue
+			}
+
+		case c == '*' || c == '_':
+			if inner, length, ok := findMarkdownDelimited(text, i, rest[:1]); ok {
+				flush()
+				nodes = append(nodes, parseMarkdownInline(inner, withMark(marks, Mark{Type: EmMark}))...)
+				i += length
+				continue
+			}
+
+		case c == '[':
+			if label, href, length, ok := findMarkdownLink(rest); ok {
+				flush()
+				if strings.HasPrefix(href, mentionScheme) {
+					nodes = append(nodes, newMention(strings.TrimPrefix(href, mentionScheme), label))
+					i += length
+					continue
+				}
+				if strings.HasPrefix(href, usernameScheme) {
+					username := strings.TrimPrefix(href, usernameScheme)
+					mention := newMention(username, label)
+					mention.Attrs["username"] = username
+					nodes = append(nodes, mention)
+					i += length
+					continue
+				}
+				link := Mark{Type: LinkMark, Attrs: map[string]interface{}{"href": href}}
+				nodes = append(nodes, parseMarkdownInline(label, withMark(marks, link))...)
+				i += length
+				continue
+			}
+
+			// Brackets without a link are the links of the wiki markup, e.g. [ABC-123]
+			if end := strings.IndexByte(rest, ']'); end > 1 && !strings.Contains(rest[:end], "\n") {
+				flush()
+				nodes = append(nodes, newWikiMarkup(rest[:end+1], marks))
+				i += end + 1
+				continue
+			}
+
+		// The macros and the images of the wiki markup are kept as they are
+		case c == '{' || c == '!':
+			markup := wikiMacroRegexp.FindString(rest)
+			if c == '!' {
+				markup = wikiImageRegexp.FindString(rest)
+			}
+			if markup != "" {
+				flush()
+				nodes = append(nodes, newWikiMarkup(markup, marks))
+				i += len(markup)
+				continue
+			}
+
+		case c == '<':
+			if tag := mdBreakRegexp.FindString(rest); tag != "" {
+				flush()
+				nodes = append(nodes, newBreak(true))
+				i += len(tag)
+				continue
+			}
+			if strings.HasPrefix(rest, "<u>") {
+				if end := strings.Index(rest, "</u>"); end > 3 {
+					flush()
+					nodes = append(nodes, parseMarkdownInline(rest[3:end], withMark(marks, Mark{Type: UnderlineMark}))...)
+					i += end + 4
+					continue
+				}
+			}
+			if end := strings.IndexByte(rest, '>'); end > 0 && isURL(rest[1:end]) {
+				flush()
+				href := rest[1:end]
+				link := Mark{Type: LinkMark, Attrs: map[string]interface{}{"href": href}}
+				nodes = append(nodes, newText(href, withMark(marks, link)))
+				i += end + 1
+				continue
+			}
+		}
+
+		buffer.WriteByte(c)
+		i++
+	}
+	flush()
+
+	return nodes
+}
+
+// findMarkdownDelimited looks for the closing delimiter of an emphasis which
+// starts at i, underscores inside words do not count
+func findMarkdownDelimited(text string, i int, delimiter string) (string, int, bool) {
+	start := i + len(delimiter)
+	if start >= len(text) || text[start] == ' ' || text[start] == '\n' {
+		return "", 0, false
+	}
+	if delimiter[0] == '_' && i > 0 && isAlphaNumeric(text[i-1]) {
+		return "", 0, false
+	}
+
+	for end := start + 1; end+len(delimiter) <= len(text); end++ {
+		if text[end-1] == '\\' || !strings.HasPrefix(text[end:], delimiter) {
+			continue
+		}
+		if text[end-1] == ' ' {
+			continue
+		}
+		after := end + len(delimiter)
+		// A single * must not be the start of a **
+		if len(delimiter) == 1 && after < len(text) && text[after] == delimiter[0] {
+			end++
+			continue
+		}
+		if delimiter[0] == '_' && after < len(text) && isAlphaNumeric(text[after]) {
+			continue
+		}
+
+		return text[start:end], after - i, true
+	}
+
+	return "", 0, false
+}
+
+// findMarkdownLink reads [label](href) at the start of the text
+func findMarkdownLink(text string) (string, string, int, bool) {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if i+1 >= len(text) || text[i+1] != '(' {
+				return "", "", 0, false
+			}
+			end := strings.IndexByte(text[i+2:], ')')
+			if end < 0 {
+				return "", "", 0, false
+			}
+			href := strings.TrimSpace(text[i+2 : i+2+end])
+			return text[1:i], strings.Trim(href, "<>"), i + 3 + end, true
+		}
+	}
+
+	return "", "", 0, false
+}
+
+// RenderMarkdown writes the document as Markdown
+func RenderMarkdown(doc *Node) string {
+	return renderMarkdownBlocks(doc.Content) + "\n"
+}
+
+func renderMarkdownBlocks(blocks []*Node) string {
+	rendered := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		rendered = append(rendered, renderMarkdownBlock(block))
+	}
+
+	return strings.Join(rendered, "\n\n")
+}
+
+func renderMarkdownBlock(n *Node) string {
+	switch n.Type {
+	case HeadingNode:
+		return fmt.Sprintf("%s %s", strings.Repeat("#", n.IntAttr("level", 1)), renderMarkdownInline(n.Content))
+
+	case BulletListNode, OrderedListNode:
+		return renderMarkdownList(n)
+
+	case TaskListNode:
+		return renderMarkdownTaskList(n)
+
+	case TableNode:
+		return renderMarkdownTable(n)
+
+	case CodeBlockNode:
+		code := textContent(n)
+		fence := "```"
+		for strings.Contains(code, fence) {
+			fence += "`"
+		}
+		return fmt.Sprintf("%s%s\n%s\n%s", fence, markdownCodeInfo(n), code, fence)
+
+	case WikiMacroNode:
+		return renderMacroBlock(n, renderMarkdownBlocks(n.Content))
+
+	case BlockquoteNode:
+		return prefixLines(renderMarkdownBlocks(n.Content), "> ", ">")
+
+	case RuleNode:
+		return "---"
+
+	case ParagraphNode:
+		return escapeMarkdownLineStarts(renderMarkdownInline(n.Content))
+	}
+
+	if len(n.Content) > 0 {
+		return renderMarkdownBlocks(n.Content)
+	}
+
+	return escapeMarkdown(n.Text)
+}
+
+func renderMarkdownList(list *Node) string {
+	order := list.IntAttr("order", 1)
+
+	items := make([]string, 0, len(list.Content))
+	for index, item := range list.Content {
+		marker := "-"
+		if list.Type == OrderedListNode {
+			marker = fmt.Sprintf("%d.", order+index)
+		}
+
+		content := unescapeTaskMarker(renderMarkdownListItem(item))
+		items = append(items, prefixFirstLine(content, marker+" ", strings.Repeat(" ", len(marker)+1)))
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// renderMarkdownTaskList writes the items as "- [x] text", nested task lists
+// are indented below the item before them
+func renderMarkdownTaskList(list *Node) string {
+	lines := make([]string, 0, len(list.Content))
+	for _, item := range list.Content {
+		if item.Type == TaskListNode {
+			lines = append(lines, prefixLines(renderMarkdownTaskList(item), "  ", ""))
+			continue
+		}
+
+		marker := "[ ]"
+		if isDone(item) {
+			marker = "[x]"
+		}
+		content := escapeMarkdownLineStarts(renderMarkdownInline(item.Content))
+		lines = append(lines, prefixFirstLine(content, fmt.Sprintf("- %s ", marker), "  "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderMarkdownTable uses the first row as header, Markdown tables always
+// have one. When the first row is not made of header cells, an empty header
+// row is written instead
+func renderMarkdownTable(table *Node) string {
+	rows := make([]string, 0, len(table.Content)+2)
+	for index, row := range table.Content {
+		cells := make([]string, 0, len(row.Content))
+		header := true
+		for _, cell := range row.Content {
+			cells = append(cells, renderMarkdownCell(cell))
+			header = header && cell.Type == TableHeaderNode
+		}
+
+		line := fmt.Sprintf("| %s |", strings.Join(cells, " | "))
+		if index > 0 {
+			rows = append(rows, line)
+			continue
+		}
+
+		delimiters := make([]string, len(cells))
+		for i := range delimiters {
+			delimiters[i] = "---"
+		}
+		delimiterRow := fmt.Sprintf("| %s |", strings.Join(delimiters, " | "))
+
+		if header {
+			rows = append(rows, line, delimiterRow)
+		} else {
+			empty := fmt.Sprintf("| %s |", strings.Join(make([]string, len(cells)), " | "))
+			rows = append(rows, empty, delimiterRow, line)
+		}
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// renderMarkdownCell writes the cell on a single line
+func renderMarkdownCell(cell *Node) string {
+	paragraphs := make([]string, 0, len(cell.Content))
+	for _, block := range cell.Content {
+		if block.Type == ParagraphNode {
+			paragraphs = append(paragraphs, renderMarkdownInline(block.Content))
+		} else {
+			paragraphs = append(paragraphs, escapeMarkdown(textContent(block)))
+		}
+	}
+
+	text := strings.ReplaceAll(strings.Join(paragraphs, " "), "\n", " ")
+
+	// Pipes must be escaped, even inside code spans
+	var escaped strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			escaped.WriteString(text[i : i+2])
+			i++
+			continue
+		}
+		if text[i] == '|' {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteByte(text[i])
+	}
+
+	return escaped.String()
+}
+
+// renderMarkdownListItem keeps nested lists right below the text of the item
+// so the list stays tight
+func renderMarkdownListItem(item *Node) string {
+	var content strings.Builder
+	for index, block := range item.Content {
+		if index > 0 {
+			if block.Type == BulletListNode || block.Type == OrderedListNode || block.Type == TaskListNode {
+				content.WriteString("\n")
+			} else {
+				content.WriteString("\n\n")
+			}
+		}
+		content.WriteString(renderMarkdownBlock(block))
+	}
+
+	return content.String()
+}
+
+func renderMarkdownInline(nodes []*Node) string {
+	return renderMarkRuns(nodes, 0, wrapMarkdown, renderMarkdownLeaf)
+}
+
+// wrapMarkdown writes the delimiters of the mark around the text
+func wrapMarkdown(mark *Mark, text string) string {
+	switch mark.Type {
+	case StrongMark:
+		// The * of an emphasis next to ** would be read as a single delimiter
+		if strings.HasPrefix(text, "*") || strings.HasSuffix(text, "*") {
+			return "__" + text + "__"
+		}
+		return "**" + text + "**"
+	case EmMark:
+		return "*" + text + "*"
+	case StrikeMark:
+		return "~~" + text + "~~"
+	case UnderlineMark:
+		return "<u>" + text + "</u>"
+	case LinkMark:
+		return fmt.Sprintf("[%s](%s)", text, mark.Attr("href"))
+	}
+
+	return text
+}
+
+func renderMarkdownLeaf(n *Node) string {
+	switch n.Type {
+	case TextNode:
+		switch {
+		case n.Text == "":
+			return ""
+		case n.HasMark(CodeMark):
+			return renderMarkdownCode(n.Text)
+		case isEscaped(n):
+			return "\\" + n.Text
+		}
+		return escapeMarkdown(n.Text)
+	case HardBreakNode:
+		if isExplicitBreak(n) {
+			return "<br>"
+		}
+		return "\n"
+	case MentionNode:
+		if username := n.Attr("username"); username != "" {
+			return fmt.Sprintf("[%s](%s%s)", escapeMarkdown(n.Attr("text")), usernameScheme, username)
+		}
+		return fmt.Sprintf("[%s](%s%s)", escapeMarkdown(n.Attr("text")), mentionScheme, n.Attr("id"))
+	case InlineCardNode:
+		return fmt.Sprintf("<%s>", n.Attr("url"))
+	case WikiMarkupNode:
+		return n.Attr("markup")
+	case EmojiNode, DateNode, StatusNode:
+		return escapeMarkdown(atomText(n))
+	}
+
+	return renderMarkdownInline(n.Content)
+}
+
+// renderMarkdownCode writes a code span with a fence longer than the
+// backticks of the code
+func renderMarkdownCode(code string) string {
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+
+	return fence + code + fence
+}
+
+// escapeMarkdown protects the characters which would be read as formatting,
+// the other ones are left alone so the text reads the same, e.g. a * between
+// spaces or an underscore inside a word
+func escapeMarkdown(text string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		rest := text[i:]
+
+		escape := false
+		switch c {
+		case '`':
+			escape = true
+		case '\\':
+			escape = i+1 == len(text) || isPunct(text[i+1])
+		case '*', '_':
+			double := rest[:1] + rest[:1]
+			_, _, single := findMarkdownDelimited(text, i, rest[:1])
+			_, _, paired := findMarkdownDelimited(text, i, double)
+			escape = single || paired && strings.HasPrefix(rest, double)
+		case '~':
+			_, _, escape = findMarkdownDelimited(text, i, "~~")
+			escape = escape && strings.HasPrefix(rest, "~~")
+		case '[':
+			escape = strings.IndexByte(rest, ']') > 0
+		case '{':
+			escape = wikiMacroRegexp.MatchString(rest)
+		case '!':
+			escape = wikiImageRegexp.MatchString(rest)
+		case '<':
+			end := strings.IndexByte(rest, '>')
+			escape = end > 0 && isURL(rest[1:end]) || mdBreakRegexp.MatchString(rest) || strings.HasPrefix(rest, "<u>")
+		}
+
+		if escape {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteByte(c)
+	}
+
+	return escaped.String()
+}
+
+// unescapeTaskMarker keeps the "[ ]" or "[x]" starting the text of a list
+// item which could not become a task list, e.g. because the other items are
+// not tasks, so the marker is still read as a task when it is parsed again
+func unescapeTaskMarker(text string) string {
+	if match := escapedTaskRegexp.FindStringSubmatch(text); match != nil {
+		return fmt.Sprintf("[%s] ", match[1]) + text[len(match[0]):]
+	}
+
+	return text
+}
+
+// escapeMarkdownLineStarts protects the lines of a paragraph which would be
+// read as another block, like "# not a heading"
+func escapeMarkdownLineStarts(text string) string {
+	lines := strings.Split(text, "\n")
+	for index, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, ">"),
+			strings.HasPrefix(line, "- "), strings.HasPrefix(line, "+ "), strings.HasPrefix(line, "|"),
+			line == "-" || line == "+", mdRuleRegexp.MatchString(line):
+			lines[index] = "\\" + line
+		case mdOrderedRegexp.MatchString(line):
+			match := mdOrderedRegexp.FindStringSubmatch(line)
+			lines[index] = match[1] + "\\" + line[len(match[1]):]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func prefixLines(text string, prefix string, emptyPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for index, line := range lines {
+		if line == "" {
+			lines[index] = emptyPrefix
+		} else {
+			lines[index] = prefix + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// prefixFirstLine puts the marker before the first line and indents the others
+func prefixFirstLine(text string, first string, others string) string {
+	lines := strings.Split(text, "\n")
+	for index, line := range lines {
+		switch {
+		case index == 0:
+			lines[index] = first + line
+		case line != "":
+			lines[index] = others + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// isPunct tells whether the character is an ASCII punctuation, the ones a
+// backslash escapes in both Markdown and the wiki markup
+func isPunct(c byte) bool {
+	return c >= '!' && c <= '/' || c >= ':' && c <= '@' || c >= '[' && c <= '`' || c >= '{' && c <= '~'
+}
+
+func isAlphaNumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isURL(text string) bool {
+	return strings.HasPrefix(text, "http://") || strings.HasPrefix(text, "https://") || strings.HasPrefix(text, "mailto:")
+}
@@ -0,0 +1,196 @@
+package markup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Each document of testdata is parsed from the Markdown typed by the user and
+// from the wiki markup stored by Jira, then rendered back to both formats
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		fixture      string
+		markdown     string
+		wiki         string
+		wantMarkdown string
+		wantWiki     string
+	}{
+		{
+			fixture:      "tasklist.json",
+			markdown:     "- [ ] Write the tests\n- [x] Fix the parser",
+			wiki:         "* [ ] Write the tests\n* [x] Fix the parser",
+			wantMarkdown: "- [ ] Write the tests\n- [x] Fix the parser\n",
+			wantWiki:     "* [ ] Write the tests\n* [x] Fix the parser",
+		},
+		{
+			fixture:      "indentedcode.json",
+			markdown:     "Run:\n\n    go build ./...\n\n    go test ./...\n",
+			wiki:         "Run:\n\n{code}\ngo build ./...\n\ngo test ./...\n{code}",
+			wantMarkdown: "Run:\n\n```\ngo build ./...\n\ngo test ./...\n```\n",
+			wantWiki:     "Run:\n\n{code}\ngo build ./...\n\ngo test ./...\n{code}",
+		},
+		{
+			fixture:      "mention.json",
+			markdown:     "Reviewed by [@John Doe](accountid:5b10ac8d82e05b22cc7d4ef5)",
+			wiki:         "Reviewed by [~accountid:5b10ac8d82e05b22cc7d4ef5]",
+			wantMarkdown: "Reviewed by [@John Doe](accountid:5b10ac8d82e05b22cc7d4ef5)\n",
+			wantWiki:     "Reviewed by [~accountid:5b10ac8d82e05b22cc7d4ef5]",
+		},
+		{
+			fixture:      "pipes.json",
+			markdown:     `\|\|`,
+			wiki:         "||",
+			wantMarkdown: "\\||\n",
+			wantWiki:     `\||`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", test.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := ParseADF(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := renderADF(t, doc)
+
+			if got := renderADF(t, ParseMarkdown(test.markdown)); got != want {
+				t.Errorf("ParseMarkdown(%q)\n got %s\nwant %s", test.markdown, got, want)
+			}
+
+			fromWiki := ParseWiki(test.wiki)
+			nameMentions(fromWiki, testNames)
+			if got := renderADF(t, fromWiki); got != want {
+				t.Errorf("ParseWiki(%q)\n got %s\nwant %s", test.wiki, got, want)
+			}
+
+			if got := RenderMarkdown(doc); got != test.wantMarkdown {
+				t.Errorf("RenderMarkdown() = %q, want %q", got, test.wantMarkdown)
+			}
+			if got := RenderWiki(doc); got != test.wantWiki {
+				t.Errorf("RenderWiki() = %q, want %q", got, test.wantWiki)
+			}
+			if got := WikiToMarkdown(test.wiki, testNames); got != test.wantMarkdown {
+				t.Errorf("WikiToMarkdown(%q) = %q, want %q", test.wiki, got, test.wantMarkdown)
+			}
+		})
+	}
+}
+
+// The corpus holds each document in the formats it can be written in, every
+// format must convert to the others as they are. The wiki markup of Jira
+// Server may use macros which have no equivalent in ADF
+func TestCorpus(t *testing.T) {
+	for _, name := range []string{"description", "lossy"} {
+		t.Run(name, func(t *testing.T) {
+			markdown := readCorpus(t, name+".md")
+			wiki := strings.TrimSuffix(readCorpus(t, name+".wiki"), "\n")
+
+			if got := WikiToMarkdown(wiki, testNames); got != markdown {
+				t.Errorf("WikiToMarkdown()\n got %q\nwant %q", got, markdown)
+			}
+			if got := MarkdownToWiki(markdown); got != wiki {
+				t.Errorf("MarkdownToWiki()\n got %q\nwant %q", got, wiki)
+			}
+
+			data, err := os.ReadFile(filepath.Join("testdata", "corpus", name+".json"))
+			if os.IsNotExist(err) {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			doc, err := ParseADF(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := RenderMarkdown(doc); got != markdown {
+				t.Errorf("RenderMarkdown()\n got %q\nwant %q", got, markdown)
+			}
+			if got := RenderWiki(doc); got != wiki {
+				t.Errorf("RenderWiki()\n got %q\nwant %q", got, wiki)
+			}
+
+			// The ADF written from the other formats holds the same document
+			fromWiki := ParseWiki(wiki)
+			nameMentions(fromWiki, testNames)
+			for format, parsed := range map[string]*Node{"Markdown": ParseMarkdown(markdown), "wiki": fromWiki} {
+				written, err := ParseADF([]byte(renderADF(t, parsed)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := RenderMarkdown(written); got != markdown {
+					t.Errorf("ADF written from %s\n got %q\nwant %q", format, got, markdown)
+				}
+			}
+		})
+	}
+}
+
+// What has no equivalent in Markdown is kept as written, so editing a text
+// stored by Jira Server does not change the parts left alone
+func TestWikiRoundTrip(t *testing.T) {
+	tests := []string{
+		"See [ABC-123] and [the spec|ABC-124]",
+		"[^attachment.pdf] and [#anchor]",
+		"{color:red}x{color}",
+		"{panel:title=Note}\nSome *text*\n{panel}",
+		"{panel:title=Steps}\n* one\n* two\n{panel}",
+		"!img.png|thumbnail!",
+		`C:\temp\file`,
+		"{code:title=Foo.java|borderStyle=solid}\nclass Foo {}\n{code}",
+		"{code:java}\nclass Foo {}\n{code}",
+		"{noformat}\n*raw* [text]\n{noformat}",
+		"+under+",
+		`first\\second`,
+		`\*not bold\* and \{not a macro\}`,
+		"a | b and 2 * 3",
+		"x^2^ and H~2~O",
+		"||Issue||State||\n|[ABC-1]|{color:red}open{color}|",
+		"|no|header|\n|for|this table|",
+	}
+
+	for _, wiki := range tests {
+		markdown := WikiToMarkdown(wiki, nil)
+		if got := MarkdownToWiki(markdown); got != wiki {
+			t.Errorf("MarkdownToWiki(%q) = %q, want %q", markdown, got, wiki)
+		}
+	}
+}
+
+// testNames knows the user mentioned by the fixtures
+func testNames(accountID string) string {
+	if accountID == "5b10ac8d82e05b22cc7d4ef5" {
+		return "John Doe"
+	}
+
+	return ""
+}
+
+func readCorpus(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "corpus", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func renderADF(t *testing.T, doc *Node) string {
+	t.Helper()
+
+	data, err := RenderADF(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,373 @@
+// Package markup converts rich text between the formats used around Jira:
+// the Markdown edited by the user, the Atlassian Document Format (ADF) used by
+// Jira Cloud and the wiki markup used by Jira Server and the REST API v2.
+//
+// Every format is parsed into the same tree of nodes, then rendered into the
+// other format. The nodes follow the ADF structure so they are marshalled as
+// ADF as they are.
+package markup
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Node types
+const (
+	DocNode         = "doc"
+	ParagraphNode   = "paragraph"
+	HeadingNode     = "heading"
+	BulletListNode  = "bulletList"
+	OrderedListNode = "orderedList"
+	ListItemNode    = "listItem"
+	CodeBlockNode   = "codeBlock"
+	BlockquoteNode  = "blockquote"
+	RuleNode        = "rule"
+	TableNode       = "table"
+	TableRowNode    = "tableRow"
+	TableHeaderNode = "tableHeader"
+	TableCellNode   = "tableCell"
+	TaskListNode    = "taskList"
+	TaskItemNode    = "taskItem"
+	TextNode        = "text"
+	HardBreakNode   = "hardBreak"
+	MentionNode     = "mention"
+	EmojiNode       = "emoji"
+	InlineCardNode  = "inlineCard"
+	DateNode        = "date"
+	StatusNode      = "status"
+)
+
+// Nodes which only exist in the wiki markup. They keep what has no equivalent
+// in Markdown as it was written, so a text goes through Markdown and back
+// unchanged. RenderADF writes them as text
+const (
+	// An inline construct kept as written in the "markup" attribute, e.g. a
+	// link to an issue [ABC-123], a macro tag {color:red} or an image !a.png!
+	WikiMarkupNode = "wikiMarkup"
+
+	// A macro around blocks, like {panel:title=Note} ... {panel}, with its
+	// "name" and "params" attributes
+	WikiMacroNode = "wikiMacro"
+)
+
+// States of a task item
+const (
+	TaskTodo = "TODO"
+	TaskDone = "DONE"
+)
+
+// Mark types, applied on text nodes
+const (
+	StrongMark    = "strong"
+	EmMark        = "em"
+	CodeMark      = "code"
+	StrikeMark    = "strike"
+	UnderlineMark = "underline"
+	LinkMark      = "link"
+)
+
+// The marks in the order they wrap each other, the outer ones first. Code is
+// left to the text nodes as it can not hold other nodes
+var markOrder = []string{LinkMark, StrikeMark, StrongMark, EmMark, UnderlineMark}
+
+// Node is a block or an inline element of a document
+type Node struct {
+	Type    string                 `json:"type"`
+	Version int                    `json:"version,omitempty"`
+	Text    string                 `json:"text,omitempty"`
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+	Marks   []Mark                 `json:"marks,omitempty"`
+	Content []*Node                `json:"content,omitempty"`
+}
+
+// Mark is a formatting applied on a text node
+type Mark struct {
+	Type  string                 `json:"type"`
+	Attrs map[string]interface{} `json:"attrs,omitempty"`
+}
+
+// NewDoc creates an empty document
+func NewDoc() *Node {
+	return &Node{Type: DocNode}
+}
+
+// Append adds the children at the end of the node
+func (n *Node) Append(children ...*Node) *Node {
+	n.Content = append(n.Content, children...)
+
+	return n
+}
+
+// Attr returns the attribute as a string, or an empty string if it is not set
+func (n *Node) Attr(name string) string {
+	value, ok := n.Attrs[name]
+	if !ok || value == nil {
+		return ""
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	}
+
+	return ""
+}
+
+// IntAttr returns the attribute as a number, or the fallback if it is not set
+func (n *Node) IntAttr(name string, fallback int) int {
+	switch v := n.Attrs[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+
+	return fallback
+}
+
+// HasMark tells whether the text node has the given mark
+func (n *Node) HasMark(markType string) bool {
+	for _, mark := range n.Marks {
+		if mark.Type == markType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Mark returns the mark of the given type, or nil if the text node does not have it
+func (n *Node) Mark(markType string) *Mark {
+	for index := range n.Marks {
+		if n.Marks[index].Type == markType {
+			return &n.Marks[index]
+		}
+	}
+
+	return nil
+}
+
+// Attr returns the attribute of the mark as a string
+func (m *Mark) Attr(name string) string {
+	value, _ := m.Attrs[name].(string)
+
+	return value
+}
+
+func newText(text string, marks []Mark) *Node {
+	node := &Node{Type: TextNode, Text: text}
+	if len(marks) > 0 {
+		node.Marks = append([]Mark{}, marks...)
+	}
+
+	return node
+}
+
+func newBlock(nodeType string, children ...*Node) *Node {
+	return &Node{Type: nodeType, Content: children}
+}
+
+func newHeading(level int, children ...*Node) *Node {
+	return &Node{
+		Type:    HeadingNode,
+		Attrs:   map[string]interface{}{"level": level},
+		Content: children,
+	}
+}
+
+func newCodeBlock(language string, code string) *Node {
+	node := &Node{Type: CodeBlockNode}
+	if language != "" {
+		node.Attrs = map[string]interface{}{"language": language}
+	}
+	if code != "" {
+		node.Content = []*Node{{Type: TextNode, Text: code}}
+	}
+
+	return node
+}
+
+func newTaskItem(done bool, children ...*Node) *Node {
+	state := TaskTodo
+	if done {
+		state = TaskDone
+	}
+
+	return &Node{
+		Type:    TaskItemNode,
+		Attrs:   map[string]interface{}{"state": state},
+		Content: children,
+	}
+}
+
+// newMention creates a mention of the user, the text is displayed as "@Name"
+func newMention(id string, text string) *Node {
+	if !strings.HasPrefix(text, "@") {
+		text = "@" + text
+	}
+
+	return &Node{
+		Type:  MentionNode,
+		Attrs: map[string]interface{}{"id": id, "text": text},
+	}
+}
+
+// newTableRow creates a row of cells, each cell holds a single paragraph
+func newTableRow(header bool, cells [][]*Node) *Node {
+	cellType := TableCellNode
+	if header {
+		cellType = TableHeaderNode
+	}
+
+	row := newBlock(TableRowNode)
+	for _, cell := range cells {
+		row.Append(newBlock(cellType, newBlock(ParagraphNode, cell...)))
+	}
+
+	return row
+}
+
+// newEscaped creates a text node for a character written with a backslash, so
+// the escape is written again in the other format
+func newEscaped(c byte, marks []Mark) *Node {
+	node := newText(string(c), marks)
+	node.Attrs = map[string]interface{}{"escaped": true}
+
+	return node
+}
+
+// newWikiMarkup keeps the markup as it is written
+func newWikiMarkup(markup string, marks []Mark) *Node {
+	node := &Node{Type: WikiMarkupNode, Attrs: map[string]interface{}{"markup": markup}}
+	if len(marks) > 0 {
+		node.Marks = append([]Mark{}, marks...)
+	}
+
+	return node
+}
+
+// newBreak creates a line break, explicit ones are written "\\" in the wiki
+// markup and "<br>" in Markdown instead of a new line
+func newBreak(explicit bool) *Node {
+	node := &Node{Type: HardBreakNode}
+	if explicit {
+		node.Attrs = map[string]interface{}{"explicit": true}
+	}
+
+	return node
+}
+
+// isEscaped tells whether the text node is a character written with a backslash
+func isEscaped(n *Node) bool {
+	escaped, _ := n.Attrs["escaped"].(bool)
+
+	return escaped
+}
+
+// isExplicitBreak tells whether the line break was written explicitly
+func isExplicitBreak(n *Node) bool {
+	explicit, _ := n.Attrs["explicit"].(bool)
+
+	return explicit
+}
+
+// renderMarkRuns writes the inline nodes, the consecutive nodes sharing a mark
+// are wrapped by it once, e.g. a bold text holding a link stays a single bold
+// span. leaf writes a node without its marks, but code
+func renderMarkRuns(nodes []*Node, level int, wrap func(mark *Mark, inner string) string, leaf func(n *Node) string) string {
+	var text strings.Builder
+
+	if level == len(markOrder) {
+		for _, n := range nodes {
+			text.WriteString(leaf(n))
+		}
+		return text.String()
+	}
+
+	markType := markOrder[level]
+	for start := 0; start < len(nodes); {
+		mark := nodes[start].Mark(markType)
+		end := start + 1
+		for end < len(nodes) && sameMark(nodes[end].Mark(markType), mark) {
+			end++
+		}
+
+		inner := renderMarkRuns(nodes[start:end], level+1, wrap, leaf)
+		if mark != nil && inner != "" {
+			inner = wrap(mark, inner)
+		}
+		text.WriteString(inner)
+
+		start = end
+	}
+
+	return text.String()
+}
+
+func sameMark(a *Mark, b *Mark) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Attr("href") == b.Attr("href")
+}
+
+// isDone tells whether the task item is checked
+func isDone(n *Node) bool {
+	return n.Attr("state") == TaskDone
+}
+
+func withMark(marks []Mark, mark Mark) []Mark {
+	return append(append([]Mark{}, marks...), mark)
+}
+
+// textContent concatenates the text of every descendant of the node
+func textContent(n *Node) string {
+	switch n.Type {
+	case TextNode:
+		return n.Text
+	case MentionNode, EmojiNode, DateNode, StatusNode, InlineCardNode, WikiMarkupNode:
+		return atomText(n)
+	}
+
+	var text strings.Builder
+	for _, child := range n.Content {
+		text.WriteString(textContent(child))
+	}
+
+	return text.String()
+}
+
+// atomText is the text displayed for the inline nodes which have no content
+func atomText(n *Node) string {
+	switch n.Type {
+	case MentionNode:
+		return n.Attr("text")
+	case EmojiNode:
+		if text := n.Attr("text"); text != "" {
+			return text
+		}
+		return n.Attr("shortName")
+	case DateNode:
+		// The timestamp is in milliseconds, as a string
+		milliseconds, err := strconv.ParseInt(n.Attr("timestamp"), 10, 64)
+		if err != nil {
+			return n.Attr("timestamp")
+		}
+		return time.UnixMilli(milliseconds).UTC().Format("2006-01-02")
+	case StatusNode:
+		return strings.ToUpper(n.Attr("text"))
+	case InlineCardNode:
+		return n.Attr("url")
+	case WikiMarkupNode:
+		return n.Attr("markup")
+	}
+
+	return ""
+}
@@ -0,0 +1,823 @@
+package markup
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	wikiHeadingRegexp  = regexp.MustCompile(`^h([1-6])\.\s*(.*)$`)
+	wikiListItemRegexp = regexp.MustCompile(`^([*#-]+)\s+(.*)$`)
+	wikiCodeRegexp     = regexp.MustCompile(`^\{(code|noformat)(?::([^}]*))?\}(.*)$`)
+	wikiRuleRegexp     = regexp.MustCompile(`^-{4,}\s*$`)
+	wikiTaskRegexp     = regexp.MustCompile(`^\\?\[([ xX])\\?\]\s+`)
+	wikiMacroRegexp    = regexp.MustCompile(`^\{([a-zA-Z]+)(?::([^}\n]*))?\}`)
+	wikiImageRegexp    = regexp.MustCompile(`^![^\s!\[][^!\n]*!`)
+)
+
+// Mentions of Jira Cloud users are written [~accountid:5b10ac8d82e05b22cc7d4ef5],
+// the ones of Jira Server users [~username]
+const wikiAccountPrefix = "~accountid:"
+
+// ParseWiki reads a document written in the Jira wiki markup, as stored by
+// the REST API v2 and by Jira Server
+func ParseWiki(text string) *Node {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+
+	return NewDoc().Append(parseWikiBlocks(lines)...)
+}
+
+func parseWikiBlocks(lines []string) []*Node {
+	blocks := make([]*Node, 0)
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case wikiCodeRegexp.MatchString(trimmed):
+			var block *Node
+			block, i = parseWikiCode(lines, i)
+			blocks = append(blocks, block)
+
+		case strings.HasPrefix(trimmed, "{quote}"):
+			quoted := make([]string, 0)
+			rest := strings.TrimPrefix(trimmed, "{quote}")
+			for {
+				if end := strings.Index(rest, "{quote}"); end >= 0 {
+					quoted = append(quoted, rest[:end])
+					i++
+					break
+				}
+				quoted = append(quoted, rest)
+				i++
+				if i >= len(lines) {
+					break
+				}
+				rest = lines[i]
+			}
+			blocks = append(blocks, newBlock(BlockquoteNode, parseWikiBlocks(quoted)...))
+
+		case strings.HasPrefix(trimmed, "bq."):
+			quote := strings.TrimSpace(strings.TrimPrefix(trimmed, "bq."))
+			blocks = append(blocks, newBlock(BlockquoteNode, newBlock(ParagraphNode, parseWikiInline(quote, nil)...)))
+			i++
+
+		case findMacroBlock(lines, i) > i:
+			var block *Node
+			block, i = parseMacroBlock(lines, i, parseWikiBlocks)
+			blocks = append(blocks, block)
+
+		case wikiHeadingRegexp.MatchString(trimmed):
+			match := wikiHeadingRegexp.FindStringSubmatch(trimmed)
+			level := int(match[1][0] - '0')
+			blocks = append(blocks, newHeading(level, parseWikiInline(match[2], nil)...))
+			i++
+
+		case wikiRuleRegexp.MatchString(trimmed):
+			blocks = append(blocks, newBlock(RuleNode))
+			i++
+
+		case wikiListItemRegexp.MatchString(trimmed):
+			var list *Node
+			list, i = parseWikiList(lines, i, 1)
+			blocks = append(blocks, list)
+
+		case strings.HasPrefix(trimmed, "|"):
+			table := newBlock(TableNode)
+			rows := make([]string, 0)
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|") {
+				rows = append(rows, strings.TrimSpace(lines[i]))
+				if row := parseWikiRow(rows[len(rows)-1]); len(row.Content) > 0 {
+					table.Append(row)
+				}
+				i++
+			}
+
+			// Lines made only of pipes, like "||", have no cell to show
+			if len(table.Content) == 0 {
+				blocks = append(blocks, newBlock(ParagraphNode, parseWikiInline(strings.Join(rows, "\n"), nil)...))
+				continue
+			}
+			blocks = append(blocks, table)
+
+		default:
+			paragraph := make([]string, 0)
+			for i < len(lines) && (len(paragraph) == 0 || !startsWikiBlock(lines[i])) {
+				paragraph = append(paragraph, strings.TrimSpace(lines[i]))
+				i++
+			}
+			blocks = append(blocks, newBlock(ParagraphNode, parseWikiInline(strings.Join(paragraph, "\n"), nil)...))
+		}
+	}
+
+	return blocks
+}
+
+// startsWikiBlock tells whether the line ends the paragraph before it
+func startsWikiBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+
+	return trimmed == "" ||
+		wikiCodeRegexp.MatchString(trimmed) ||
+		strings.HasPrefix(trimmed, "{quote}") ||
+		strings.HasPrefix(trimmed, "bq.") ||
+		wikiHeadingRegexp.MatchString(trimmed) ||
+		wikiRuleRegexp.MatchString(trimmed) ||
+		wikiListItemRegexp.MatchString(trimmed) ||
+		strings.HasPrefix(trimmed, "|")
+}
+
+// parseWikiRow reads a row like "||Header||Header||" or "|cell|cell|", pipes
+// inside links and monospaced text do not split the cells
+func parseWikiRow(line string) *Node {
+	row := newBlock(TableRowNode)
+
+	cellType := ""
+	start, depth := 0, 0
+	for i := 0; i <= len(line); i++ {
+		switch {
+		case i < len(line) && line[i] == '\\':
+			i++
+			continue
+		case i < len(line) && (line[i] == '[' || strings.HasPrefix(line[i:], "{{")):
+			depth++
+			continue
+		case i < len(line) && (line[i] == ']' || strings.HasPrefix(line[i:], "}}")) && depth > 0:
+			depth--
+			continue
+		case i < len(line) && (line[i] != '|' || depth > 0):
+			continue
+		}
+
+		if cellType != "" && (i < len(line) || start < i) {
+			content := strings.TrimSpace(line[start:i])
+			row.Append(newBlock(cellType, newBlock(ParagraphNode, parseWikiInline(content, nil)...)))
+		}
+		if i == len(line) {
+			break
+		}
+
+		cellType = TableCellNode
+		if strings.HasPrefix(line[i:], "||") {
+			cellType = TableHeaderNode
+			i++
+		}
+		start = i + 1
+	}
+
+	return row
+}
+
+// findMacroBlock returns the line closing the macro which starts at the given
+// line, or -1. A macro holds blocks when its tag fills a line, e.g.
+// {panel:title=Note}, and a line below closes it, e.g. {panel}
+func findMacroBlock(lines []string, start int) int {
+	trimmed := strings.TrimSpace(lines[start])
+	match := wikiMacroRegexp.FindStringSubmatch(trimmed)
+	if match == nil || len(match[0]) != len(trimmed) {
+		return -1
+	}
+
+	closing := "{" + match[1] + "}"
+	for i := start + 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == closing {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// parseMacroBlock reads the blocks held by the macro with the parser of the
+// format, Markdown keeps the macros of the wiki markup the same way
+func parseMacroBlock(lines []string, start int, parseBlocks func(lines []string) []*Node) (*Node, int) {
+	end := findMacroBlock(lines, start)
+	match := wikiMacroRegexp.FindStringSubmatch(strings.TrimSpace(lines[start]))
+
+	macro := newBlock(WikiMacroNode, parseBlocks(lines[start+1:end])...)
+	macro.Attrs = map[string]interface{}{"name": match[1]}
+	if match[2] != "" {
+		macro.Attrs["params"] = match[2]
+	}
+
+	return macro, end + 1
+}
+
+// renderMacroBlock writes the tags of the macro around its rendered blocks
+func renderMacroBlock(macro *Node, content string) string {
+	opening, closing := macroTags(macro)
+	if content == "" {
+		return opening + "\n" + closing
+	}
+
+	return opening + "\n" + content + "\n" + closing
+}
+
+func macroTags(macro *Node) (string, string) {
+	name := macro.Attr("name")
+	if params := macro.Attr("params"); params != "" {
+		return fmt.Sprintf("{%s:%s}", name, params), fmt.Sprintf("{%s}", name)
+	}
+
+	return fmt.Sprintf("{%s}", name), fmt.Sprintf("{%s}", name)
+}
+
+func parseWikiCode(lines []string, start int) (*Node, int) {
+	match := wikiCodeRegexp.FindStringSubmatch(strings.TrimSpace(lines[start]))
+	macro, params := match[1], match[2]
+
+	closing := "{" + macro + "}"
+	code := make([]string, 0)
+	rest := match[3]
+
+	i := start
+	for {
+		if end := strings.Index(rest, closing); end >= 0 {
+			if rest[:end] != "" {
+				code = append(code, rest[:end])
+			}
+			i++
+			break
+		}
+		if i > start || rest != "" {
+			code = append(code, rest)
+		}
+		i++
+		if i >= len(lines) {
+			break
+		}
+		rest = lines[i]
+	}
+
+	return newWikiCode(macro, params, strings.Join(code, "\n")), i
+}
+
+// newWikiCode creates the block of a {code} or {noformat} macro. The
+// parameters are kept when they tell more than the language, e.g.
+// "title=Foo.java|java", to write them back
+func newWikiCode(macro string, params string, code string) *Node {
+	language := ""
+	if macro == "code" {
+		language = wikiCodeLanguage(params)
+	}
+
+	block := newCodeBlock(language, code)
+	if macro != "noformat" && (params == "" || params == language) {
+		return block
+	}
+
+	if block.Attrs == nil {
+		block.Attrs = make(map[string]interface{})
+	}
+	if macro == "noformat" {
+		block.Attrs["macro"] = macro
+	}
+	if params != "" {
+		block.Attrs["params"] = params
+	}
+
+	return block
+}
+
+// wikiCodeMacro returns the macro writing the code block and its parameters
+func wikiCodeMacro(n *Node) (string, string) {
+	if n.Attr("macro") == "noformat" {
+		return "noformat", n.Attr("params")
+	}
+	if params := n.Attr("params"); params != "" {
+		return "code", params
+	}
+
+	return "code", n.Attr("language")
+}
+
+// wikiCodeLanguage picks the language out of the code macro parameters,
+// e.g. "title=Example|java" or "language=go"
+func wikiCodeLanguage(params string) string {
+	for _, param := range strings.Split(params, "|") {
+		key, value, found := strings.Cut(param, "=")
+		if !found {
+			return strings.TrimSpace(key)
+		}
+		if strings.TrimSpace(key) == "language" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// parseWikiList reads the items having the given depth, deeper items become
+// lists nested into the item before them
+func parseWikiList(lines []string, start int, depth int) (*Node, int) {
+	match := wikiListItemRegexp.FindStringSubmatch(strings.TrimSpace(lines[start]))
+	marker := match[1][depth-1]
+
+	list := newBlock(BulletListNode)
+	if marker == '#' {
+		list.Type = OrderedListNode
+	}
+
+	// The state of each task item, or an empty string for the usual items
+	tasks := make([]string, 0)
+
+	i := start
+	for i < len(lines) {
+		match := wikiListItemRegexp.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if match == nil || len(match[1]) < depth || match[1][depth-1] != marker {
+			break
+		}
+
+		if len(match[1]) > depth {
+			var nested *Node
+			nested, i = parseWikiList(lines, i, depth+1)
+			if len(list.Content) == 0 {
+				list.Append(newBlock(ListItemNode))
+				tasks = append(tasks, "")
+			}
+			last := list.Content[len(list.Content)-1]
+			last.Append(nested)
+			continue
+		}
+
+		text, task := match[2], ""
+		if marker := wikiTaskRegexp.FindStringSubmatch(text); marker != nil && list.Type == BulletListNode {
+			text, task = text[len(marker[0]):], marker[1]
+		}
+		tasks = append(tasks, task)
+
+		list.Append(newBlock(ListItemNode, newBlock(ParagraphNode, parseWikiInline(text, nil)...)))
+		i++
+	}
+
+	return toTaskList(list, tasks), i
+}
+
+// parseWikiInline reads the text of a block, every text node gets the given
+// marks in addition to its own
+func parseWikiInline(text string, marks []Mark) []*Node {
+	nodes := make([]*Node, 0)
+
+	var buffer strings.Builder
+	flush := func() {
+		if buffer.Len() > 0 {
+			nodes = append(nodes, newText(buffer.String(), marks))
+			buffer.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		rest := text[i:]
+
+		switch {
+		case strings.HasPrefix(rest, `\\`):
+			flush()
+			nodes = append(nodes, newBreak(true))
+			i += 2
+			continue
+
+		// Only punctuation is escaped, the backslash stays before the other
+		// characters, e.g. in C:\temp
+		case c == '\\' && i+1 < len(text) && isPunct(text[i+1]):
+			flush()
+			nodes = append(nodes, newEscaped(text[i+1], marks))
+			i += 2
+			continue
+
+		case c == '\n':
+			flush()
+			nodes = append(nodes, newBreak(false))
+			i++
+			continue
+
+		case strings.HasPrefix(rest, "{{"):
+			if end := strings.Index(rest[2:], "}}"); end > 0 {
+				flush()
+				nodes = append(nodes, newText(rest[2:2+end], withMark(marks, Mark{Type: CodeMark})))
+				i += end + 4
+				continue
+			}
+
+		// The macros used inline, e.g. {color:red}, and the images are kept
+		// as they are written
+		case c == '{' || c == '!':
+			markup := wikiMacroRegexp.FindString(rest)
+			if c == '!' {
+				markup = wikiImageRegexp.FindString(rest)
+			}
+			if markup != "" {
+				flush()
+				nodes = append(nodes, newWikiMarkup(markup, marks))
+				i += len(markup)
+				continue
+			}
+
+		case strings.HasPrefix(rest, "[~"):
+			if end := strings.IndexByte(rest, ']'); end > 2 {
+				flush()
+				nodes = append(nodes, parseWikiMention(rest[1:end]))
+				i += end + 1
+				continue
+			}
+
+		case c == '[':
+			if end := strings.IndexByte(rest, ']'); end > 1 && !strings.Contains(rest[:end], "\n") {
+				flush()
+				i += end + 1
+
+				label, href, found := strings.Cut(rest[1:end], "|")
+				if !found {
+					href = label
+				}
+				if isURL(strings.TrimSpace(href)) {
+					link := Mark{Type: LinkMark, Attrs: map[string]interface{}{"href": strings.TrimSpace(href)}}
+					nodes = append(nodes, parseWikiInline(label, withMark(marks, link))...)
+					continue
+				}
+
+				// Links to issues, attachments or anchors have no equivalent
+				nodes = append(nodes, newWikiMarkup(rest[:end+1], marks))
+				continue
+			}
+
+		case c == '*' || c == '_' || c == '-' || c == '+':
+			if inner, length, ok := findWikiDelimited(text, i); ok {
+				flush()
+				nodes = append(nodes, parseWikiInline(inner, withMark(marks, Mark{Type: wikiMarks[c]}))...)
+				i += length
+				continue
+			}
+		}
+
+		buffer.WriteByte(c)
+		i++
+	}
+	flush()
+
+	return nodes
+}
+
+// parseWikiMention reads the user of a mention, the username of the Jira
+// Server users is kept to write it back
+func parseWikiMention(user string) *Node {
+	if strings.HasPrefix(user, wikiAccountPrefix) {
+		id := strings.TrimPrefix(user, wikiAccountPrefix)
+		return newMention(id, id)
+	}
+
+	username := strings.TrimPrefix(user, "~")
+	mention := newMention(username, username)
+	mention.Attrs["username"] = username
+
+	return mention
+}
+
+// UserNames gives the display name of a Jira Cloud user from the account id,
+// an empty name keeps the account id
+type UserNames func(accountID string) string
+
+// nameMentions displays the name of the Jira Cloud users mentioned in the
+// document, the wiki markup only stores their account id
+func nameMentions(n *Node, names UserNames) {
+	if n.Type == MentionNode && n.Attr("username") == "" {
+		if name := names(n.Attr("id")); name != "" {
+			n.Attrs["text"] = "@" + name
+		}
+	}
+
+	for _, child := range n.Content {
+		nameMentions(child, names)
+	}
+}
+
+// The wiki delimiters and the mark they stand for
+var wikiMarks = map[byte]string{
+	'*': StrongMark,
+	'_': EmMark,
+	'-': StrikeMark,
+	'+': UnderlineMark,
+}
+
+// findWikiDelimited looks for the closing delimiter of an effect starting at
+// i, effects must start and end at word boundaries
+func findWikiDelimited(text string, i int) (string, int, bool) {
+	delimiter := text[i]
+	if i > 0 && isAlphaNumeric(text[i-1]) {
+		return "", 0, false
+	}
+
+	start := i + 1
+	if start >= len(text) || text[start] == ' ' || text[start] == '\n' || text[start] == delimiter {
+		return "", 0, false
+	}
+
+	for end := start + 1; end < len(text); end++ {
+		if text[end] == '\n' {
+			return "", 0, false
+		}
+		if text[end] != delimiter || text[end-1] == ' ' || text[end-1] == '\\' {
+			continue
+		}
+		if end+1 < len(text) && isAlphaNumeric(text[end+1]) {
+			continue
+		}
+
+		return text[start:end], end + 1 - i, true
+	}
+
+	return "", 0, false
+}
+
+// RenderWiki writes the document in the Jira wiki markup
+func RenderWiki(doc *Node) string {
+	return renderWikiBlocks(doc.Content, "")
+}
+
+func renderWikiBlocks(blocks []*Node, listPrefix string) string {
+	rendered := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		rendered = append(rendered, renderWikiBlock(block, listPrefix))
+	}
+
+	return strings.Join(rendered, "\n\n")
+}
+
+func renderWikiBlock(n *Node, listPrefix string) string {
+	switch n.Type {
+	case HeadingNode:
+		return fmt.Sprintf("h%d. %s", n.IntAttr("level", 1), renderWikiInline(n.Content))
+
+	case BulletListNode, OrderedListNode, TaskListNode:
+		return renderWikiList(n, listPrefix)
+
+	case TableNode:
+		return renderWikiTable(n)
+
+	case CodeBlockNode:
+		macro, params := wikiCodeMacro(n)
+		if params != "" {
+			return fmt.Sprintf("{%s:%s}\n%s\n{%s}", macro, params, textContent(n), macro)
+		}
+		return fmt.Sprintf("{%s}\n%s\n{%s}", macro, textContent(n), macro)
+
+	case WikiMacroNode:
+		return renderMacroBlock(n, renderWikiBlocks(n.Content, ""))
+
+	case BlockquoteNode:
+		return fmt.Sprintf("{quote}\n%s\n{quote}", renderWikiBlocks(n.Content, ""))
+
+	case RuleNode:
+		return "----"
+
+	case ParagraphNode:
+		return escapeWikiLineStarts(renderWikiInline(n.Content))
+	}
+
+	if len(n.Content) > 0 {
+		return renderWikiBlocks(n.Content, listPrefix)
+	}
+
+	return escapeWiki(n.Text)
+}
+
+// renderWikiList writes every item on a single line, prefixed by the markers
+// of all the lists it is nested into
+func renderWikiList(list *Node, listPrefix string) string {
+	marker := "*"
+	if list.Type == OrderedListNode {
+		marker = "#"
+	}
+	prefix := listPrefix + marker
+
+	lines := make([]string, 0, len(list.Content))
+	for _, item := range list.Content {
+		// The wiki markup has no task lists, the state is written as text
+		switch item.Type {
+		case TaskListNode:
+			lines = append(lines, renderWikiList(item, prefix))
+			continue
+		case TaskItemNode:
+			state := "[ ]"
+			if isDone(item) {
+				state = "[x]"
+			}
+			lines = append(lines, fmt.Sprintf("%s %s %s", prefix, state, renderWikiListText(renderWikiInline(item.Content))))
+			continue
+		}
+
+		text := make([]string, 0)
+		nested := make([]string, 0)
+
+		for _, block := range item.Content {
+			switch block.Type {
+			case BulletListNode, OrderedListNode, TaskListNode:
+				nested = append(nested, renderWikiList(block, prefix))
+			case ParagraphNode:
+				text = append(text, renderWikiInline(block.Content))
+			default:
+				text = append(text, renderWikiBlock(block, ""))
+			}
+		}
+
+		content := unescapeTaskMarker(renderWikiListText(strings.Join(text, "\n")))
+		if content != "" || len(nested) == 0 {
+			lines = append(lines, fmt.Sprintf("%s %s", prefix, content))
+		}
+		lines = append(lines, nested...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderWikiListText keeps the text of an item on a single line, as items
+// can not span several lines
+func renderWikiListText(text string) string {
+	return strings.ReplaceAll(text, "\n", ` \\ `)
+}
+
+// renderWikiTable writes the header cells as "||Header||" and the other ones
+// as "|cell|"
+func renderWikiTable(table *Node) string {
+	rows := make([]string, 0, len(table.Content))
+	for _, row := range table.Content {
+		var line strings.Builder
+		delimiter := "|"
+		for _, cell := range row.Content {
+			delimiter = "|"
+			if cell.Type == TableHeaderNode {
+				delimiter = "||"
+			}
+
+			paragraphs := make([]string, 0, len(cell.Content))
+			for _, block := range cell.Content {
+				if block.Type == ParagraphNode {
+					paragraphs = append(paragraphs, renderMarkRuns(block.Content, 0, wrapWiki, renderWikiCellLeaf))
+				} else {
+					paragraphs = append(paragraphs, escapeWikiCell(textContent(block)))
+				}
+			}
+
+			// An empty cell is written with a space so it is not read as a header
+			content := renderWikiListText(strings.Join(paragraphs, "\n"))
+			if content == "" {
+				content = " "
+			}
+			line.WriteString(delimiter + content)
+		}
+		line.WriteString(delimiter)
+		rows = append(rows, line.String())
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+func renderWikiInline(nodes []*Node) string {
+	return renderMarkRuns(nodes, 0, wrapWiki, renderWikiLeaf)
+}
+
+// wrapWiki writes the effect of the mark around the text
+func wrapWiki(mark *Mark, text string) string {
+	switch mark.Type {
+	case StrongMark:
+		return "*" + text + "*"
+	case EmMark:
+		return "_" + text + "_"
+	case StrikeMark:
+		return "-" + text + "-"
+	case UnderlineMark:
+		return "+" + text + "+"
+	case LinkMark:
+		href := mark.Attr("href")
+		if text == href {
+			return fmt.Sprintf("[%s]", href)
+		}
+		return fmt.Sprintf("[%s|%s]", text, href)
+	}
+
+	return text
+}
+
+func renderWikiLeaf(n *Node) string {
+	switch n.Type {
+	case TextNode:
+		switch {
+		case n.Text == "":
+			return ""
+		case n.HasMark(CodeMark):
+			return "{{" + n.Text + "}}"
+		// An escaped backslash would make a line break
+		case isEscaped(n) && n.Text != "\\":
+			return "\\" + n.Text
+		}
+		return escapeWiki(n.Text)
+	case HardBreakNode:
+		if isExplicitBreak(n) {
+			return `\\`
+		}
+		return "\n"
+	case MentionNode:
+		if username := n.Attr("username"); username != "" {
+			return fmt.Sprintf("[~%s]", username)
+		}
+		return fmt.Sprintf("[%s%s]", wikiAccountPrefix, n.Attr("id"))
+	case InlineCardNode:
+		return fmt.Sprintf("[%s]", n.Attr("url"))
+	case WikiMarkupNode:
+		return n.Attr("markup")
+	case EmojiNode, DateNode, StatusNode:
+		return escapeWiki(atomText(n))
+	}
+
+	return renderWikiInline(n.Content)
+}
+
+// renderWikiCellLeaf also escapes the pipes of the text, they would end the cell
+func renderWikiCellLeaf(n *Node) string {
+	if n.Type == TextNode && !n.HasMark(CodeMark) && !isEscaped(n) {
+		return escapeWikiCell(n.Text)
+	}
+
+	return renderWikiLeaf(n)
+}
+
+// escapeWiki protects the characters which would be read as markup, the
+// other ones are left alone so the text reads the same
+func escapeWiki(text string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		rest := text[i:]
+
+		escape := false
+		switch c {
+		case '[':
+			escape = strings.IndexByte(rest, ']') > 1
+		case '{':
+			escape = strings.HasPrefix(rest, "{{") && strings.Contains(rest[2:], "}}") || wikiMacroRegexp.MatchString(rest)
+		case '!':
+			escape = wikiImageRegexp.MatchString(rest)
+		case '*', '_', '-', '+':
+			_, _, escape = findWikiDelimited(text, i)
+		}
+
+		if escape {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteByte(c)
+	}
+
+	return escaped.String()
+}
+
+func escapeWikiCell(text string) string {
+	return strings.ReplaceAll(escapeWiki(text), "|", `\|`)
+}
+
+// escapeWikiLineStarts protects the lines of a paragraph which would be read
+// as another block, like "* not a list" or "h1. not a heading"
+func escapeWikiLineStarts(text string) string {
+	lines := strings.Split(text, "\n")
+	for index, line := range lines {
+		switch {
+		case wikiListItemRegexp.MatchString(line), wikiRuleRegexp.MatchString(line), strings.HasPrefix(line, "|"):
+			lines[index] = "\\" + line
+		case wikiHeadingRegexp.MatchString(line), strings.HasPrefix(line, "bq."):
+			// Letters can not be escaped, the dot is
+			dot := strings.IndexByte(line, '.')
+			lines[index] = line[:dot] + "\\" + line[dot:]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// WikiToMarkdown converts a text stored by Jira so it can be edited as
+// Markdown, names tells how the mentioned users are displayed and may be nil
+func WikiToMarkdown(text string, names UserNames) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+
+	doc := ParseWiki(text)
+	if names != nil {
+		nameMentions(doc, names)
+	}
+
+	return RenderMarkdown(doc)
+}
+
+// MarkdownToWiki converts an edited Markdown text back to the wiki markup
+func MarkdownToWiki(text string) string {
+	if strings.TrimSpace(text) == "" {
+		return ""
+	}
+
+	return RenderWiki(ParseMarkdown(text))
+}
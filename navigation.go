@@ -0,0 +1,89 @@
+package main
+
+// Navigation remembers which issues were opened in the Details view, so the
+// user can go back and forth like in a browser
+type Navigation struct {
+	current string
+	back    []string
+	forward []string
+}
+
+// Visit opens a new issue, the forward history is dropped like in a browser
+func (n *Navigation) Visit(key string) {
+	if key == n.current {
+		return
+	}
+	if n.current != "" {
+		n.back = append(n.back, n.current)
+	}
+	n.current = key
+	n.forward = nil
+}
+
+// Back returns the previous issue, or an empty string if there is none
+func (n *Navigation) Back() string {
+	if len(n.back) == 0 {
+		return ""
+	}
+	n.forward = append(n.forward, n.current)
+	n.current = n.back[len(n.back)-1]
+	n.back = n.back[:len(n.back)-1]
+
+	return n.current
+}
+
+// Forward returns the issue we came back from, or an empty string if there is none
+func (n *Navigation) Forward() string {
+	if len(n.forward) == 0 {
+		return ""
+	}
+	n.back = append(n.back, n.current)
+	n.current = n.forward[len(n.forward)-1]
+	n.forward = n.forward[:len(n.forward)-1]
+
+	return n.current
+}
+
+// Previous returns the issue Back would return, without moving
+func (n *Navigation) Previous() string {
+	if len(n.back) == 0 {
+		return ""
+	}
+
+	return n.back[len(n.back)-1]
+}
+
+// Next returns the issue Forward would return, without moving
+func (n *Navigation) Next() string {
+	if len(n.forward) == 0 {
+		return ""
+	}
+
+	return n.forward[len(n.forward)-1]
+}
+
+// Current returns the issue being displayed
+func (n *Navigation) Current() string {
+	return n.current
+}
+
+// Keys lists every issue of the history, the most recent first
+func (n *Navigation) Keys() []string {
+	all := append([]string{}, n.forward...)
+	all = append(all, n.back...)
+	if n.current != "" {
+		all = append(all, n.current)
+	}
+
+	seen := make(map[string]bool, len(all))
+	keys := make([]string, 0, len(all))
+	for index := len(all) - 1; index >= 0; index-- {
+		if seen[all[index]] {
+			continue
+		}
+		seen[all[index]] = true
+		keys = append(keys, all[index])
+	}
+
+	return keys
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log"
+
+	ui "github.com/awesome-gocui/gocui"
+)
+
+// Creates a popup list to choose one (or several) of the given items
+func createPickerView(g *ui.Gui, o CreateDialogOptions, items []string) {
+	tw, th := g.Size()
+	v, err := g.SetView(PickerView, tw/4, (th/2)-10, (tw*3)/4, (th/2)+6, 0)
+	if err != nil && err != ui.ErrUnknownView {
+		log.Panicln("Error while creating picker view", err)
+	}
+
+	g.Cursor = false
+
+	v.FrameRunes = []rune{'═', '║', '╔', '╗', '╚', '╝'}
+	v.Subtitle = PickerDescription
+
+	// The value is passed to the next action, like the project code of a list
+	PickerList = CreateList(v, false)
+	PickerList.SetCode(o.value)
+	PickerList.SetTitle(o.title)
+	PickerList.SetItems(items)
+	PickerList.Focus(g)
+}
+
+func deletePickerView(g *ui.Gui) {
+	if err := g.DeleteView(PickerView); err != nil {
+		log.Panicln("Error while deleting picker view", err)
+	}
+}
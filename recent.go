@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+)
+
+const (
+	// The issues opened in the Details view are saved in this file of the
+	// state directory
+	RecentStateFile = "recent.json"
+
+	// How many issues opened locally are remembered
+	RecentIssuesLimit = 30
+)
+
+// The keys of the issues opened in the Details view, the most recent first
+var recentIssues []string
+
+// loadRecentIssues restores the issues opened during the previous sessions
+func loadRecentIssues() {
+	data, err := os.ReadFile(getStatePath(RecentStateFile))
+	if err != nil {
+		return
+	}
+
+	keys := make([]string, 0)
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return
+	}
+
+	recentIssues = keys
+}
+
+func saveRecentIssues() error {
+	path := getStatePath(RecentStateFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(recentIssues)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// addRecentIssue moves the issue to the top of the recent issues, only the
+// last RecentIssuesLimit are kept
+func addRecentIssue(g *ui.Gui, key string) {
+	keys := []string{key}
+	for _, recent := range recentIssues {
+		if recent != key && len(keys) < RecentIssuesLimit {
+			keys = append(keys, recent)
+		}
+	}
+	recentIssues = keys
+
+	if err := saveRecentIssues(); err != nil {
+		ShowStatus(g, fmt.Sprintf("Failed to save the recent issues: %s", err))
+	}
+}
+
+// makeRecentJQL finds the issues opened locally and the ones in the history of
+// the user on the server, the most recently viewed first
+func makeRecentJQL(statusQL string) string {
+	clause := "issue in issueHistory()"
+	if len(recentIssues) > 0 {
+		clause = fmt.Sprintf("(%s OR key IN (%s))", clause, strings.Join(recentIssues, ","))
+	}
+
+	return fmt.Sprintf("%s %s ORDER BY lastViewed DESC", clause, statusQL)
+}
+
+// sortRecentIssues puts the issues opened locally first, in the order they
+// were opened, followed by the rest of the server history
+func sortRecentIssues(issues []jira.Issue) []jira.Issue {
+	byKey := make(map[string]jira.Issue, len(issues))
+	for _, issue := range issues {
+		byKey[issue.Key] = issue
+	}
+
+	seen := make(map[string]bool, len(issues))
+	sorted := make([]jira.Issue, 0, len(issues))
+	for _, key := range recentIssues {
+		if issue, ok := byKey[key]; ok && !seen[key] {
+			sorted = append(sorted, issue)
+			seen[key] = true
+		}
+	}
+
+	for _, issue := range issues {
+		if !seen[issue.Key] {
+			sorted = append(sorted, issue)
+			seen[issue.Key] = true
+		}
+	}
+
+	return sorted
+}
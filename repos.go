@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+	git "github.com/go-git/go-git/v5"
+	config "github.com/gookit/config/v2"
+)
+
+var (
+	// The repository used by the git action in progress, chosen among the
+	// repositories of the project
+	gitRepoPath = "."
+
+	// Called once a repository is chosen in the repository picker
+	repoPickerNext func(g *ui.Gui) error
+	repoChoices    []string
+
+	// The list focused before the repository picker was opened
+	repoPickerReturnList *List
+)
+
+// openRepository opens the repository chosen for the git action in progress
+func openRepository() (*git.Repository, error) {
+	return openRepositoryAt(gitRepoPath)
+}
+
+// openRepositoryAt opens the repository containing the path
+func openRepositoryAt(path string) (*git.Repository, error) {
+	return git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+}
+
+// getRepositoryPaths returns the repositories of the project, listed in
+// projects.<code>.repos. Without any, the repository of the working directory
+// is used
+func getRepositoryPaths(projectCode string) []string {
+	configured := config.Strings(getReposPath(projectCode))
+	if len(configured) == 0 {
+		if root, err := getRepositoryRoot("."); err == nil {
+			return []string{root}
+		}
+		return nil
+	}
+
+	paths := make([]string, 0, len(configured))
+	for _, path := range configured {
+		if root, err := getRepositoryRoot(expandHome(path)); err == nil {
+			paths = append(paths, root)
+		}
+	}
+
+	return paths
+}
+
+func getReposPath(projectCode string) string {
+	return fmt.Sprintf("%s.%s.repos", ProjectsKey, strings.ToLower(projectCode))
+}
+
+// getRepositoryRoot returns the top directory of the repository containing
+// the path
+func getRepositoryRoot(path string) (string, error) {
+	repo, err := openRepositoryAt(path)
+	if err != nil {
+		return "", err
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+
+	return w.Filesystem.Root(), nil
+}
+
+// getIssuesRepositoryPaths returns the repositories of the projects of the
+// issues, each one once
+func getIssuesRepositoryPaths(issues []jira.Issue) []string {
+	seenProjects := make(map[string]bool)
+	seenPaths := make(map[string]bool)
+
+	paths := make([]string, 0)
+	for _, issue := range issues {
+		projectCode, _, _ := strings.Cut(issue.Key, "-")
+		if seenProjects[projectCode] {
+			continue
+		}
+		seenProjects[projectCode] = true
+
+		for _, path := range getRepositoryPaths(projectCode) {
+			if !seenPaths[path] {
+				seenPaths[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	return paths
+}
+
+// selectRepository chooses the repository of the project before running
+// next, the user picks one when the project has several
+func selectRepository(g *ui.Gui, v *ui.View, projectCode string, next func(g *ui.Gui) error) error {
+	paths := getRepositoryPaths(projectCode)
+
+	switch len(paths) {
+	case 0:
+		ShowStatus(g, fmt.Sprintf("No git repository for %s, add one to %s", projectCode, getReposPath(projectCode)))
+		return nil
+	case 1:
+		gitRepoPath = paths[0]
+		return next(g)
+	}
+
+	repoChoices = paths
+	repoPickerNext = next
+
+	repoPickerReturnList = IssuesList
+	if v.Name() == DetailsView {
+		repoPickerReturnList = DetailsList
+	}
+	repoPickerReturnList.Unfocus()
+
+	items := make([]string, len(paths))
+	for index, path := range paths {
+		items[index] = fmt.Sprintf("%s (%s)", filepath.Base(path), path)
+	}
+
+	createPickerView(g, CreateDialogOptions{title: fmt.Sprintf("%s| %s ", RepoPickerTitle, projectCode)}, items)
+
+	return nil
+}
+
+// chooseRepository is called with the index of the item chosen in the
+// repository picker
+func chooseRepository(g *ui.Gui, index int) error {
+	repoPickerReturnList.Focus(g)
+
+	if index >= len(repoChoices) {
+		return nil
+	}
+
+	gitRepoPath = repoChoices[index]
+
+	return repoPickerNext(g)
+}
+
+func cancelRepositoryPicker(g *ui.Gui) {
+	if repoPickerReturnList != nil {
+		repoPickerReturnList.Focus(g)
+	}
+}
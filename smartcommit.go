@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+)
+
+var (
+	// A smart commit command starts a line or follows a space, e.g. #comment.
+	// #12 is a reference to a pull request, not a command
+	smartCommandRegexp = regexp.MustCompile(`(^|\s)#([A-Za-z][A-Za-z0-9_-]*)`)
+
+	smartTimeRegexp = regexp.MustCompile(`^\d+(\.\d+)?[wdhm]$`)
+)
+
+// SmartCommand is a command of a smart commit message, e.g. #time with the
+// arguments "1h 30m Review"
+type SmartCommand struct {
+	Name string
+	Args string
+}
+
+// parseSmartCommands finds the commands of the message, the arguments of a
+// command run until the next command or the end of the line
+func parseSmartCommands(message string) []SmartCommand {
+	commands := make([]SmartCommand, 0)
+
+	for _, line := range strings.Split(message, "\n") {
+		matches := smartCommandRegexp.FindAllStringSubmatchIndex(line, -1)
+		for index, match := range matches {
+			end := len(line)
+			if index+1 < len(matches) {
+				end = matches[index+1][0]
+			}
+
+			commands = append(commands, SmartCommand{
+				Name: strings.ToLower(line[match[4]:match[5]]),
+				Args: strings.TrimSpace(line[match[5]:end]),
+			})
+		}
+	}
+
+	return commands
+}
+
+// validateSmartCommit checks the commands of the message before committing,
+// Jira silently ignores the ones it does not understand. The transitions are
+// checked against the committed issue and the issues of the saved projects
+// mentioned in the message, so words like UTF-8 are not taken for issues. It
+// must run outside of the main loop
+func validateSmartCommit(message string, issueKey string, projects []string) error {
+	commands := parseSmartCommands(message)
+	if len(commands) == 0 {
+		return nil
+	}
+
+	keys := findMentionedIssueKeys(message, issueKey, projects)
+	if len(keys) == 0 {
+		return fmt.Errorf("#%s needs an issue key in the message", commands[0].Name)
+	}
+
+	transitions := make(map[string][]jira.Transition)
+
+	for _, command := range commands {
+		switch command.Name {
+		case "comment":
+			if command.Args == "" {
+				return fmt.Errorf("#comment needs a text")
+			}
+			continue
+		case "time":
+			if !isSmartTime(command.Args) {
+				return fmt.Errorf("#time needs a time like 1h 30m")
+			}
+			continue
+		}
+
+		for _, key := range keys {
+			if _, ok := transitions[key]; !ok {
+				available, err := GetTransitions(key)
+				if err != nil {
+					return fmt.Errorf("failed to load the transitions of %s: %w", key, err)
+				}
+				transitions[key] = available
+			}
+
+			if len(transitions[key]) == 0 {
+				return fmt.Errorf("#%s is not a transition of %s, it has none", command.Name, key)
+			}
+			if findSmartTransition(transitions[key], command.Name) == nil {
+				return fmt.Errorf("#%s is not a transition of %s, expected %s", command.Name, key, formatSmartTransitions(transitions[key]))
+			}
+		}
+	}
+
+	return nil
+}
+
+// isSmartTime tells whether the arguments of #time start with a time, e.g.
+// "1h 30m Review"
+func isSmartTime(args string) bool {
+	fields := strings.Fields(args)
+
+	return len(fields) > 0 && smartTimeRegexp.MatchString(fields[0])
+}
+
+// findSmartTransition matches the command the way Jira does: the name of the
+// transition with hyphens for the spaces (#start-progress), or its first word
+// when no other transition starts with it (#start)
+func findSmartTransition(transitions []jira.Transition, command string) *jira.Transition {
+	var found *jira.Transition
+	count := 0
+
+	for index, transition := range transitions {
+		name := strings.ToLower(transition.Name)
+		if strings.ReplaceAll(name, " ", "-") == command {
+			return &transitions[index]
+		}
+
+		if first, _, _ := strings.Cut(name, " "); first == command {
+			found = &transitions[index]
+			count++
+		}
+	}
+
+	if count == 1 {
+		return found
+	}
+
+	return nil
+}
+
+// formatSmartTransitions lists the commands of the transitions, e.g.
+// "#start-progress, #done"
+func formatSmartTransitions(transitions []jira.Transition) string {
+	commands := make([]string, len(transitions))
+	for index, transition := range transitions {
+		commands[index] = "#" + strings.ReplaceAll(strings.ToLower(transition.Name), " ", "-")
+	}
+
+	return strings.Join(commands, ", ")
+}
+
+// findMentionedIssueKeys returns the keys of the text which are the issue
+// being committed or belong to one of the projects, each one once
+func findMentionedIssueKeys(text string, issueKey string, projects []string) []string {
+	seen := make(map[string]bool)
+
+	keys := make([]string, 0)
+	for _, key := range issueKeyRegexp.FindAllString(text, -1) {
+		projectCode, _, _ := strings.Cut(key, "-")
+		if seen[key] || (key != issueKey && !isSavedProject(projects, projectCode)) {
+			continue
+		}
+
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	ui "github.com/awesome-gocui/gocui"
+)
+
+// How long a message stays in the status bar
+const StatusMessageDuration = 5 * time.Second
+
+var (
+	StatusBar *ui.View
+
+	statusMessage     string
+	statusMessageSeed int
+)
+
+// layoutStatusBar keeps the status bar on the last line of the terminal, below
+// the Issues and Details views
+func layoutStatusBar(g *ui.Gui) error {
+	tw, th := g.Size()
+
+	v, err := g.SetView(StatusBarView, -1, th-2, tw, th, 0)
+	if err != nil && err != ui.ErrUnknownView {
+		return err
+	}
+
+	if StatusBar == nil {
+		v.Frame = false
+		StatusBar = v
+	}
+
+	renderStatusBar()
+
+	return nil
+}
+
+// ShowStatus displays the message in the status bar for a few seconds
+func ShowStatus(g *ui.Gui, message string) {
+	statusMessageSeed++
+	seed := statusMessageSeed
+	statusMessage = message
+	renderStatusBar()
+
+	time.AfterFunc(StatusMessageDuration, func() {
+		g.Update(func(g *ui.Gui) error {
+			if seed == statusMessageSeed {
+				statusMessage = ""
+				renderStatusBar()
+			}
+			return nil
+		})
+	})
+}
+
+// startStatusBarTicker redraws the status bar every second, so the elapsed
+// time of the running timer stays up to date
+func startStatusBarTicker(g *ui.Gui) {
+	go func() {
+		for range time.Tick(time.Second) {
+			// The timer is changed by the main loop, it is only read there
+			g.Update(func(g *ui.Gui) error {
+				if RunningTimer != nil {
+					renderStatusBar()
+				}
+				return nil
+			})
+		}
+	}()
+}
+
+// renderStatusBar writes the message on the left and the timer on the right
+func renderStatusBar() {
+	if StatusBar == nil {
+		return
+	}
+
+	width, _ := StatusBar.Size()
+
+	timer := ""
+	if RunningTimer != nil {
+		timer = fmt.Sprintf("⏱ %s %s ", RunningTimer.IssueKey, formatElapsed(RunningTimer.Elapsed()))
+	}
+
+	message := fmt.Sprintf(" %s", statusMessage)
+	padding := width - len([]rune(message)) - len([]rune(timer))
+	if padding < 1 {
+		padding = 1
+	}
+
+	StatusBar.Clear()
+	if _, err := fmt.Fprintf(StatusBar, "%s%s%s", message, spaces(padding), timer); err != nil {
+		log.Println("Error while rendering the status bar", err)
+	}
+}
+
+// formatElapsed writes the duration as a clock, e.g. 01:05:09
+func formatElapsed(d time.Duration) string {
+	seconds := int(d.Seconds())
+
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, (seconds/60)%60, seconds%60)
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ui "github.com/awesome-gocui/gocui"
+)
+
+const (
+	TimesheetSummaryWidth = 30
+	TimesheetDayLayout    = "Mon 01-02"
+)
+
+// Timesheet is the time logged by the current user per issue and per day
+type Timesheet struct {
+	Days []time.Time
+	Rows []TimesheetRow
+}
+
+// TimesheetRow is the time logged on an issue, in seconds by day (DateLayout)
+type TimesheetRow struct {
+	Key     string
+	Summary string
+	Seconds map[string]int
+}
+
+var (
+	TimesheetList *List
+
+	// The first day of the week displayed in the timesheet view
+	timesheetWeek time.Time
+
+	// The list focused before the timesheet was opened
+	timesheetReturnList *List
+)
+
+// BuildTimesheet collects the worklogs of the current user between the two
+// dates, both included. Only the issues found by worklogAuthor are fetched
+func BuildTimesheet(from time.Time, to time.Time) (*Timesheet, error) {
+	from, to = startOfDay(from), startOfDay(to)
+
+	me, err := GetCurrentUser()
+	if err != nil {
+		return nil, err
+	}
+
+	issues, err := SearchWorklogIssues(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	timesheet := &Timesheet{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		timesheet.Days = append(timesheet.Days, day)
+	}
+
+	for _, issue := range issues {
+		records, err := GetWorklogs(issue.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		row := TimesheetRow{
+			Key:     issue.Key,
+			Summary: issue.Fields.Summary,
+			Seconds: make(map[string]int),
+		}
+
+		for _, record := range records {
+			if record.Author == nil || record.Author.AccountID != me.AccountID || record.Started == nil {
+				continue
+			}
+
+			day := startOfDay(time.Time(*record.Started))
+			if day.Before(from) || day.After(to) {
+				continue
+			}
+			row.Seconds[day.Format(DateLayout)] += record.TimeSpentSeconds
+		}
+
+		if row.Total() > 0 {
+			timesheet.Rows = append(timesheet.Rows, row)
+		}
+	}
+
+	sort.SliceStable(timesheet.Rows, func(i, j int) bool {
+		return timesheet.Rows[i].Key < timesheet.Rows[j].Key
+	})
+
+	return timesheet, nil
+}
+
+func (r TimesheetRow) Total() int {
+	total := 0
+	for _, seconds := range r.Seconds {
+		total += seconds
+	}
+
+	return total
+}
+
+// DayTotal is the time logged on every issue during the day
+func (t *Timesheet) DayTotal(day time.Time) int {
+	total := 0
+	for _, row := range t.Rows {
+		total += row.Seconds[day.Format(DateLayout)]
+	}
+
+	return total
+}
+
+func (t *Timesheet) Total() int {
+	total := 0
+	for _, row := range t.Rows {
+		total += row.Total()
+	}
+
+	return total
+}
+
+// Lines renders the timesheet as a grid of hours, one line per issue
+func (t *Timesheet) Lines() []string {
+	header := fmt.Sprintf("%-12s %-*s", "Issue", TimesheetSummaryWidth, "Summary")
+	for _, day := range t.Days {
+		header += fmt.Sprintf(" %9s", day.Format(TimesheetDayLayout))
+	}
+	header += fmt.Sprintf(" %7s", "Total")
+
+	lines := []string{header}
+	for _, row := range t.Rows {
+		line := fmt.Sprintf("%-12s %-*s", row.Key, TimesheetSummaryWidth, truncate(row.Summary, TimesheetSummaryWidth))
+		for _, day := range t.Days {
+			line += fmt.Sprintf(" %9s", formatHours(row.Seconds[day.Format(DateLayout)]))
+		}
+		line += fmt.Sprintf(" %7s", formatHours(row.Total()))
+		lines = append(lines, line)
+	}
+
+	total := fmt.Sprintf("%-12s %-*s", "Total", TimesheetSummaryWidth, "")
+	for _, day := range t.Days {
+		total += fmt.Sprintf(" %9s", formatHours(t.DayTotal(day)))
+	}
+	total += fmt.Sprintf(" %7s", formatHours(t.Total()))
+
+	return append(lines, "", total)
+}
+
+// WriteCSV exports the hours with one column per day, the empty cells are
+// the days without work logged
+func (t *Timesheet) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"Issue", "Summary"}
+	for _, day := range t.Days {
+		header = append(header, day.Format(DateLayout))
+	}
+	if err := writer.Write(append(header, "Total")); err != nil {
+		return err
+	}
+
+	for _, row := range t.Rows {
+		record := []string{row.Key, row.Summary}
+		for _, day := range t.Days {
+			record = append(record, formatDecimalHours(row.Seconds[day.Format(DateLayout)]))
+		}
+		if err := writer.Write(append(record, formatDecimalHours(row.Total()))); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// WriteMarkdown exports the timesheet as a Markdown table
+func (t *Timesheet) WriteMarkdown(w io.Writer) error {
+	header := []string{"Issue", "Summary"}
+	align := []string{"---", "---"}
+	for _, day := range t.Days {
+		header = append(header, day.Format(TimesheetDayLayout))
+		align = append(align, "---:")
+	}
+	header = append(header, "Total")
+	align = append(align, "---:")
+
+	lines := []string{
+		fmt.Sprintf("| %s |", strings.Join(header, " | ")),
+		fmt.Sprintf("| %s |", strings.Join(align, " | ")),
+	}
+
+	for _, row := range t.Rows {
+		cells := []string{row.Key, strings.ReplaceAll(row.Summary, "|", "\\|")}
+		for _, day := range t.Days {
+			cells = append(cells, formatDecimalHours(row.Seconds[day.Format(DateLayout)]))
+		}
+		cells = append(cells, formatDecimalHours(row.Total()))
+		lines = append(lines, fmt.Sprintf("| %s |", strings.Join(cells, " | ")))
+	}
+
+	totals := []string{"**Total**", ""}
+	for _, day := range t.Days {
+		totals = append(totals, formatDecimalHours(t.DayTotal(day)))
+	}
+	totals = append(totals, fmt.Sprintf("**%s**", formatDecimalHours(t.Total())))
+	lines = append(lines, fmt.Sprintf("| %s |", strings.Join(totals, " | ")))
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+
+	return err
+}
+
+// Open the timesheet of the current week
+func OpenTimesheet(g *ui.Gui, v *ui.View) error {
+	switch v.Name() {
+	case ProjectsView:
+		timesheetReturnList = ProjectsList
+	case StatusesView:
+		timesheetReturnList = StatusesList
+	default:
+		timesheetReturnList = IssuesList
+	}
+	timesheetReturnList.Unfocus()
+
+	tw, th := g.Size()
+	view, err := g.SetView(TimesheetView, 2, 1, tw-3, th-4, 0)
+	if err != nil && err != ui.ErrUnknownView {
+		log.Panicln("Error while creating timesheet view", err)
+	}
+
+	view.FrameRunes = []rune{'═', '║', '╔', '╗', '╚', '╝'}
+	view.Subtitle = TimesheetDescription
+
+	TimesheetList = CreateList(view, false)
+	TimesheetList.Focus(g)
+
+	timesheetWeek = startOfWeek(time.Now())
+
+	return loadTimesheet(g)
+}
+
+func PrevTimesheetWeek(g *ui.Gui, v *ui.View) error {
+	timesheetWeek = timesheetWeek.AddDate(0, 0, -7)
+
+	return loadTimesheet(g)
+}
+
+func NextTimesheetWeek(g *ui.Gui, v *ui.View) error {
+	timesheetWeek = timesheetWeek.AddDate(0, 0, 7)
+
+	return loadTimesheet(g)
+}
+
+func CloseTimesheet(g *ui.Gui, v *ui.View) error {
+	if err := g.DeleteView(TimesheetView); err != nil {
+		log.Panicln("Error while deleting timesheet view", err)
+	}
+
+	timesheetReturnList.Focus(g)
+
+	return nil
+}
+
+func loadTimesheet(g *ui.Gui) error {
+	from, to := timesheetWeek, timesheetWeek.AddDate(0, 0, 6)
+	title := fmt.Sprintf("%s| %s - %s ", TimesheetTitle, from.Format(DateLayout), to.Format(DateLayout))
+
+	TimesheetList.SetTitle(fmt.Sprintf("%s| Fetching... ", title))
+
+	g.Update(func(g *ui.Gui) error {
+		if _, err := g.View(TimesheetView); err != nil {
+			return nil
+		}
+
+		timesheet, err := BuildTimesheet(from, to)
+		if err != nil {
+			TimesheetList.SetTitle(fmt.Sprintf("%s| Error! ", title))
+			return TimesheetList.RefreshItems([]string{err.Error()})
+		}
+
+		TimesheetList.SetTitle(title)
+		TimesheetList.Reset()
+		TimesheetList.SetItems(timesheet.Lines())
+
+		return nil
+	})
+
+	return nil
+}
+
+// parseTimesheetRange reads the dates of the command line, the current week
+// is used when they are missing
+func parseTimesheetRange(from string, to string) (time.Time, time.Time, error) {
+	start := startOfWeek(time.Now())
+	end := start.AddDate(0, 0, 6)
+
+	if from != "" {
+		date, err := time.ParseInLocation(DateLayout, from, time.Local)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid --from date, expected %s", DateLayout)
+		}
+		start = date
+		if to == "" {
+			end = start.AddDate(0, 0, 6)
+		}
+	}
+
+	if to != "" {
+		date, err := time.ParseInLocation(DateLayout, to, time.Local)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid --to date, expected %s", DateLayout)
+		}
+		end = date
+	}
+
+	if end.Before(start) {
+		return start, end, fmt.Errorf("--to must not be before --from")
+	}
+
+	return start, end, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// startOfWeek returns the Monday of the week of the given day
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+
+	return day.AddDate(0, 0, -offset)
+}
+
+// formatHours writes the seconds as hours for the grid, e.g. 1.5h
+func formatHours(seconds int) string {
+	if seconds == 0 {
+		return "-"
+	}
+
+	return formatDecimalHours(seconds) + "h"
+}
+
+// formatDecimalHours writes the seconds as hours with at most two decimals
+func formatDecimalHours(seconds int) string {
+	if seconds == 0 {
+		return ""
+	}
+
+	hours := math.Round(float64(seconds)/36) / 100
+
+	return strconv.FormatFloat(hours, 'f', -1, 64)
+}
+
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+
+	return string(runes[:width-1]) + "…"
+}
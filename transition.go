@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+)
+
+// findTransition finds the transition by its name or by the name of the
+// status it leads to, ignoring the case, e.g. "Start progress" or
+// "In Progress"
+func findTransition(transitions []jira.Transition, name string) *jira.Transition {
+	for index, transition := range transitions {
+		if strings.EqualFold(transition.Name, name) {
+			return &transitions[index]
+		}
+	}
+
+	for index, transition := range transitions {
+		if strings.EqualFold(transition.To.Name, name) {
+			return &transitions[index]
+		}
+	}
+
+	return nil
+}
+
+// findIssueTransition finds the transition of the issue matching the name, the
+// error lists the available transitions when none matches
+func findIssueTransition(issueKey string, name string) (*jira.Transition, error) {
+	transitions, err := GetTransitions(issueKey)
+	if err != nil {
+		return nil, err
+	}
+
+	transition := findTransition(transitions, name)
+	if transition == nil && len(transitions) == 0 {
+		return nil, fmt.Errorf("no transition %q, the issue has none", name)
+	}
+	if transition == nil {
+		return nil, fmt.Errorf("no transition %q, expected one of %s", name, formatTransitionNames(transitions))
+	}
+
+	return transition, nil
+}
+
+func formatTransitionNames(transitions []jira.Transition) string {
+	names := make([]string, len(transitions))
+	for index, transition := range transitions {
+		names[index] = transition.Name
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	config "github.com/gookit/config/v2"
+)
+
+// IssueNode is one row of the issues tree, it knows its children so the
+// progress can be rolled up to the parent rows
+type IssueNode struct {
+	Issue    jira.Issue
+	Children []*IssueNode
+	depth    int
+}
+
+// IssueTree holds the epic > story > sub-task hierarchy of the current issues
+type IssueTree struct {
+	Roots    []*IssueNode
+	nodes    map[string]*IssueNode
+	expanded map[string]bool
+}
+
+// BuildIssueTree nests the given issues under their parents, issues whose parent
+// is not part of the given list become roots
+func BuildIssueTree(issues []jira.Issue, expanded map[string]bool) *IssueTree {
+	tree := &IssueTree{
+		nodes:    make(map[string]*IssueNode, len(issues)),
+		expanded: expanded,
+	}
+	if tree.expanded == nil {
+		tree.expanded = make(map[string]bool)
+	}
+
+	// Keep the order of the search result, skip duplicates
+	ordered := make([]*IssueNode, 0, len(issues))
+	for _, issue := range issues {
+		if _, ok := tree.nodes[issue.Key]; ok {
+			continue
+		}
+		node := &IssueNode{Issue: issue}
+		tree.nodes[issue.Key] = node
+		ordered = append(ordered, node)
+	}
+
+	// Sub-tasks come embedded in their parent, no need to fetch them
+	for _, node := range ordered {
+		if node.Issue.Fields == nil {
+			continue
+		}
+		for _, subtask := range node.Issue.Fields.Subtasks {
+			if _, ok := tree.nodes[subtask.Key]; ok {
+				continue
+			}
+			fields := subtask.Fields
+			fields.Parent = &jira.Parent{ID: node.Issue.ID, Key: node.Issue.Key}
+			child := &IssueNode{Issue: jira.Issue{ID: subtask.ID, Key: subtask.Key, Fields: &fields}}
+			tree.nodes[subtask.Key] = child
+			ordered = append(ordered, child)
+		}
+	}
+
+	for _, node := range ordered {
+		parent, ok := tree.nodes[getParentKey(node.Issue)]
+		if ok && parent != node {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		tree.Roots = append(tree.Roots, node)
+	}
+
+	for _, root := range tree.Roots {
+		root.setDepth(0)
+	}
+
+	return tree
+}
+
+func (n *IssueNode) setDepth(depth int) {
+	n.depth = depth
+	for _, child := range n.Children {
+		child.setDepth(depth + 1)
+	}
+}
+
+// Progress counts the done issues among all descendants of the node
+func (n *IssueNode) Progress() (int, int) {
+	done, total := 0, 0
+	for _, child := range n.Children {
+		if isIssueDone(child.Issue) {
+			done++
+		}
+		total++
+
+		childDone, childTotal := child.Progress()
+		done += childDone
+		total += childTotal
+	}
+
+	return done, total
+}
+
+// Rows returns the visible rows of the tree, children of collapsed nodes are skipped
+func (t *IssueTree) Rows() []string {
+	rows := make([]string, 0, len(t.nodes))
+	for _, root := range t.Roots {
+		rows = t.appendRows(rows, root)
+	}
+
+	return rows
+}
+
+func (t *IssueTree) appendRows(rows []string, n *IssueNode) []string {
+	rows = append(rows, t.formatRow(n))
+	if !t.expanded[n.Issue.Key] {
+		return rows
+	}
+	for _, child := range n.Children {
+		rows = t.appendRows(rows, child)
+	}
+
+	return rows
+}
+
+func (t *IssueTree) formatRow(n *IssueNode) string {
+	indent := strings.Repeat("  ", n.depth)
+
+	marker := " "
+	if len(n.Children) > 0 {
+		marker = "▸"
+		if t.expanded[n.Issue.Key] {
+			marker = "▾"
+		}
+	}
+
+	row := fmt.Sprintf("%s%s %s", indent, marker, formatListedIssue(n.Issue))
+
+	if len(n.Children) > 0 {
+		done, total := n.Progress()
+		row = fmt.Sprintf("%s (%d) [%d/%d %d%%]", row, len(n.Children), done, total, (done*100)/total)
+	}
+
+	return row
+}
+
+// SetExpanded opens or closes the node, returns false if there is nothing to toggle
+func (t *IssueTree) SetExpanded(key string, expanded bool) bool {
+	node, ok := t.nodes[key]
+	if !ok || len(node.Children) == 0 || t.expanded[key] == expanded {
+		return false
+	}
+	t.expanded[key] = expanded
+
+	return true
+}
+
+// Issue returns the issue of the row with the key, nil if it is not listed
+func (t *IssueTree) Issue(key string) *jira.Issue {
+	node, ok := t.nodes[key]
+	if !ok {
+		return nil
+	}
+
+	return &node.Issue
+}
+
+// ParentKey returns the key of the parent row, if the parent is part of the tree
+func (t *IssueTree) ParentKey(key string) string {
+	node, ok := t.nodes[key]
+	if !ok {
+		return ""
+	}
+	parentKey := getParentKey(node.Issue)
+	if _, ok := t.nodes[parentKey]; !ok {
+		return ""
+	}
+
+	return parentKey
+}
+
+// getParentKey reads the parent from the parent field first (sub-tasks and
+// team-managed projects) then falls back to the epic link custom field
+func getParentKey(issue jira.Issue) string {
+	if issue.Fields == nil {
+		return ""
+	}
+	if issue.Fields.Parent != nil && issue.Fields.Parent.Key != "" {
+		return issue.Fields.Parent.Key
+	}
+	if issue.Fields.Epic != nil && issue.Fields.Epic.Key != "" {
+		return issue.Fields.Epic.Key
+	}
+	if epicKey, ok := issue.Fields.Unknowns.Value(getEpicLinkField()); ok {
+		if key, ok := epicKey.(string); ok {
+			return key
+		}
+	}
+
+	return ""
+}
+
+func getEpicLinkField() string {
+	return config.String(EpicLinkFieldKey, DefaultEpicLinkField)
+}
+
+// Epic Link is searched with cf[10014] syntax, not with the field id
+func getEpicLinkClause() string {
+	return fmt.Sprintf("cf[%s]", strings.TrimPrefix(getEpicLinkField(), "customfield_"))
+}
+
+func isEpic(issue jira.Issue) bool {
+	return issue.Fields != nil && strings.EqualFold(issue.Fields.Type.Name, "epic")
+}
+
+func isIssueDone(issue jira.Issue) bool {
+	return issue.Fields != nil &&
+		issue.Fields.Status != nil &&
+		issue.Fields.Status.StatusCategory.Key == jira.StatusCategoryComplete
+}
+
+// getEpicKeys collects the keys of epics in the list, their children are not
+// embedded in the search result and must be fetched separately
+func getEpicKeys(issues []jira.Issue) []string {
+	keys := make([]string, 0)
+	for _, issue := range issues {
+		if isEpic(issue) {
+			keys = append(keys, issue.Key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
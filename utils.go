@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
 	"sort"
 	"strings"
 
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
 	ui "github.com/awesome-gocui/gocui"
 	color "github.com/gookit/color"
 	config "github.com/gookit/config/v2"
@@ -15,6 +20,9 @@ import (
 	keyring "github.com/zalando/go-keyring"
 )
 
+// An issue key is a word of its own, so the end of xABC-1 or ABC-1x is not one
+var issueKeyRegexp = regexp.MustCompile(`\b[A-Z][A-Z0-9_]*-[0-9]+\b`)
+
 func getPaths() string {
 	configHome := os.Getenv("XDG_CONFIG_HOME")
 	configDir := fmt.Sprintf("%s/%s", configHome, ProjectName)
@@ -23,17 +31,33 @@ func getPaths() string {
 	return configPath
 }
 
+// getStatePath returns the path of a file kept between runs, like the running
+// timer, under $XDG_STATE_HOME (~/.local/state by default)
+func getStatePath(name string) string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		home, _ := os.UserHomeDir()
+		stateHome = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(stateHome, ProjectName, name)
+}
+
 func initConfigSetup() {
 	red := color.FgRed.Render
 
-	configPath := getPaths()
+	if err := loadConfig(); err != nil {
+		log.Fatalf("Missing config file, create one at %s", red(ConfigPathMsg))
+	}
+}
 
+// loadConfig reads the config file, the commands which do without it, like
+// the commit hook, ignore the error
+func loadConfig() error {
 	config.WithOptions(config.ParseEnv)
 	config.AddDriver(yaml.Driver)
 
-	if err := config.LoadFiles(configPath); err != nil {
-		log.Fatalf("Missing config file, create one at %s", red(ConfigPathMsg))
-	}
+	return config.LoadFiles(getPaths())
 }
 
 func GetJiraCredentials() (string, string, string, error) {
@@ -112,7 +136,34 @@ func loadProjects() {
 		IssuesList.SetTitle("No issues")
 	}
 
-	ProjectsList.SetItems(savedProjects)
+	ProjectsList.SetItems(appendBuiltinProjects(savedProjects))
+}
+
+// The entries always listed after the saved projects, handled by MakeJQL
+var BuiltinProjects = []string{WatchingKey, RecentKey}
+
+func appendBuiltinProjects(projects []string) []string {
+	for _, code := range BuiltinProjects {
+		if !isSavedProject(projects, code) {
+			projects = append(projects, strings.ToUpper(code))
+		}
+	}
+
+	return projects
+}
+
+func isSavedProject(projects []string, code string) bool {
+	for _, project := range projects {
+		if strings.EqualFold(project, code) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isBuiltinProject(code string) bool {
+	return isSavedProject(BuiltinProjects, code)
 }
 
 func makeTabNames(name string) string {
@@ -128,6 +179,14 @@ func makeTabNames(name string) string {
 	return "Something went wrong in making name"
 }
 
+func makeIssuesTitle() string {
+	if TreeMode {
+		return " Issues (tree) "
+	}
+
+	return " Issues "
+}
+
 func FetchIssues(g *ui.Gui, code string) error {
 	IssuesList.Reset()
 	IssuesList.SetCode(code)
@@ -139,17 +198,23 @@ func FetchIssues(g *ui.Gui, code string) error {
 		return err
 	}
 
+	CurrentIssues = issues
+	refreshLocalBranchKeys()
+
 	if len(issues) == 0 {
 		IssuesList.SetTitle(fmt.Sprintf("No issues in %s", code))
 		return nil
 	}
 
+	if TreeMode {
+		return loadIssuesTree()
+	}
+
+	IssuesTree = nil
+
 	parsedIssues := make([]string, len(issues))
 	for index, issue := range issues {
-		key := issue.Key
-		summary := issue.Fields.Summary
-		row := fmt.Sprintf("%-2s %s", key, summary)
-		parsedIssues[index] = row
+		parsedIssues[index] = formatListedIssue(issue)
 	}
 
 	IssuesList.SetItems(parsedIssues)
@@ -157,6 +222,97 @@ func FetchIssues(g *ui.Gui, code string) error {
 	return nil
 }
 
+// Builds the hierarchy from the current issues, children of epics are fetched
+// in one go before drawing
+func loadIssuesTree() error {
+	issues := CurrentIssues
+
+	epicKeys := getEpicKeys(issues)
+	if len(epicKeys) > 0 {
+		children, err := SearchChildIssues(epicKeys)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, children...)
+	}
+
+	var expanded map[string]bool
+	if IssuesTree != nil {
+		expanded = IssuesTree.expanded
+	}
+
+	IssuesTree = BuildIssueTree(issues, expanded)
+	IssuesList.SetItems(IssuesTree.Rows())
+
+	return nil
+}
+
+func formatIssueRow(issue jira.Issue) string {
+	return fmt.Sprintf("%-2s %s", issue.Key, issue.Fields.Summary)
+}
+
+// findListedIssue returns the issue of the issues list with the key, including
+// the children loaded by the tree mode
+func findListedIssue(key string) *jira.Issue {
+	if key == "" {
+		return nil
+	}
+
+	if IssuesTree != nil {
+		if issue := IssuesTree.Issue(key); issue != nil {
+			return issue
+		}
+	}
+
+	for index := range CurrentIssues {
+		if CurrentIssues[index].Key == key {
+			return &CurrentIssues[index]
+		}
+	}
+
+	return nil
+}
+
+// formatListedIssue flags the issues having a local git branch
+func formatListedIssue(issue jira.Issue) string {
+	if localBranchKeys[issue.Key] {
+		return fmt.Sprintf("%s %s", LocalBranchMarker, formatIssueRow(issue))
+	}
+
+	return fmt.Sprintf("  %s", formatIssueRow(issue))
+}
+
+// redrawIssueRows formats the current issues again, keeping the selection
+func redrawIssueRows() error {
+	if IssuesTree != nil {
+		return IssuesList.RefreshItems(IssuesTree.Rows())
+	}
+
+	rows := make([]string, len(CurrentIssues))
+	for index, issue := range CurrentIssues {
+		rows[index] = formatListedIssue(issue)
+	}
+
+	return IssuesList.RefreshItems(rows)
+}
+
+// isKnownProjectKey tells whether the key belongs to a saved project or to the
+// project of the current issue
+func isKnownProjectKey(key string) bool {
+	projectCode, _, _ := strings.Cut(key, "-")
+
+	if CurrentIssue != nil && strings.HasPrefix(CurrentIssue.Key, projectCode+"-") {
+		return true
+	}
+
+	return isSavedProject(GetSavedProjects(), projectCode)
+}
+
+// Rows can be prefixed by tree markers, so the key is searched instead of split
+func issueKeyFromRow(row string) string {
+	return issueKeyRegexp.FindString(row)
+}
+
 func FetchStatuses(g *ui.Gui, code string) error {
 	StatusesList.Reset()
 	StatusesList.SetCode(code)
@@ -210,6 +366,31 @@ func writeConfigToFile() {
 	}
 }
 
+// openExternally opens the file or the URL with the default application of
+// the system, without waiting for it to exit
+func openExternally(target string) error {
+	opener := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		opener = "open"
+	}
+
+	return exec.Command(opener, target).Start()
+}
+
+// expandHome replaces the leading ~ of the path by the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[1:])
+}
+
 func spaces(n int) string {
 	var s bytes.Buffer
 	for i := 0; i < n; i++ {
@@ -218,6 +399,27 @@ func spaces(n int) string {
 	return s.String()
 }
 
+// Splits the text into lines no longer than width, words are not broken
+func wrapText(text string, width int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 || width <= 0 {
+		return []string{text}
+	}
+
+	lines := make([]string, 0)
+	line := words[0]
+	for _, word := range words[1:] {
+		if len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line = fmt.Sprintf("%s %s", line, word)
+	}
+
+	return append(lines, line)
+}
+
 // func startSpinner(g *ui.Gui, v *ui.View) {
 // 	spinnerInterval := 110 * time.Millisecond
 
@@ -264,3 +466,83 @@ func isDeleteView(v *ui.View) bool {
 func isCreatingBranchView(v *ui.View) bool {
 	return strings.Contains(v.Title, NewBranchTitle)
 }
+
+func isNewLinkView(v *ui.View) bool {
+	return strings.Contains(v.Title, NewLinkTitle)
+}
+
+func isLinkTypePickerView(v *ui.View) bool {
+	return strings.Contains(v.Title, LinkTypeTitle)
+}
+
+func isRemoveLinkView(v *ui.View) bool {
+	return strings.Contains(v.Title, RemoveLinkTitle)
+}
+
+func isAssignView(v *ui.View) bool {
+	return strings.Contains(v.Title, AssignTitle)
+}
+
+func isAssignErrorView(v *ui.View) bool {
+	return strings.Contains(v.Title, AssignErrorTitle)
+}
+
+func isEditFieldView(v *ui.View) bool {
+	return strings.Contains(v.Title, EditFieldTitle)
+}
+
+func isLogWorkView(v *ui.View) bool {
+	return strings.Contains(v.Title, LogWorkTitle)
+}
+
+func isEditWorklogView(v *ui.View) bool {
+	return strings.Contains(v.Title, EditWorklogTitle)
+}
+
+func isDeleteWorklogView(v *ui.View) bool {
+	return strings.Contains(v.Title, DeleteWorklogTitle)
+}
+
+func isAttachFileView(v *ui.View) bool {
+	return strings.Contains(v.Title, AttachFileTitle)
+}
+
+func isChangelogFilterView(v *ui.View) bool {
+	return strings.Contains(v.Title, ChangelogTitle)
+}
+
+func isCopyView(v *ui.View) bool {
+	return strings.Contains(v.Title, CopyTitle)
+}
+
+func isJumpToIssueView(v *ui.View) bool {
+	return strings.Contains(v.Title, JumpToIssueTitle)
+}
+
+func isCheckoutBranchView(v *ui.View) bool {
+	return strings.Contains(v.Title, CheckoutBranchTitle)
+}
+
+func isBaseBranchView(v *ui.View) bool {
+	return strings.Contains(v.Title, BaseBranchTitle)
+}
+
+func isBaseRefView(v *ui.View) bool {
+	return strings.Contains(v.Title, BaseRefTitle)
+}
+
+func isDirtyTreeView(v *ui.View) bool {
+	return strings.Contains(v.Title, DirtyTreeTitle)
+}
+
+func isRepoPickerView(v *ui.View) bool {
+	return strings.Contains(v.Title, RepoPickerTitle)
+}
+
+func isWorktreeView(v *ui.View) bool {
+	return strings.Contains(v.Title, WorktreeTitle)
+}
+
+func isCommitView(v *ui.View) bool {
+	return strings.Contains(v.Title, CommitTitle)
+}
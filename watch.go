@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+)
+
+// Start or stop watching the selected issue
+func ToggleWatch(g *ui.Gui, v *ui.View) error {
+	issueKey := getActionIssueKey(v)
+	if issueKey == "" {
+		return nil
+	}
+
+	ShowStatus(g, fmt.Sprintf("Updating the watchers of %s...", issueKey))
+
+	g.Update(func(g *ui.Gui) error {
+		issue, err := GetIssueByKey(issueKey)
+		if err != nil {
+			ShowStatus(g, fmt.Sprintf("Failed to load %s: %s", issueKey, err))
+			return nil
+		}
+
+		me, err := GetCurrentUser()
+		if err != nil {
+			ShowStatus(g, err.Error())
+			return nil
+		}
+
+		if isWatching(issue) {
+			err = RemoveWatcher(issueKey, me.AccountID)
+		} else {
+			err = AddWatcher(issueKey, me.AccountID)
+		}
+		if err != nil {
+			ShowStatus(g, fmt.Sprintf("Failed to update the watchers of %s: %s", issueKey, err))
+			return nil
+		}
+
+		if isWatching(issue) {
+			ShowStatus(g, fmt.Sprintf("Stopped watching %s", issueKey))
+		} else {
+			ShowStatus(g, fmt.Sprintf("Watching %s", issueKey))
+		}
+
+		return refreshAfterWatch(g, issueKey)
+	})
+
+	return nil
+}
+
+// Vote for the selected issue, or remove the vote
+func ToggleVote(g *ui.Gui, v *ui.View) error {
+	issueKey := getActionIssueKey(v)
+	if issueKey == "" {
+		return nil
+	}
+
+	ShowStatus(g, fmt.Sprintf("Updating the votes of %s...", issueKey))
+
+	g.Update(func(g *ui.Gui) error {
+		issue, err := GetIssueByKey(issueKey)
+		if err != nil {
+			ShowStatus(g, fmt.Sprintf("Failed to load %s: %s", issueKey, err))
+			return nil
+		}
+
+		_, voted := getVotes(issue)
+		if voted {
+			err = RemoveVote(issueKey)
+		} else {
+			err = AddVote(issueKey)
+		}
+		if err != nil {
+			ShowStatus(g, fmt.Sprintf("Failed to vote on %s: %s", issueKey, err))
+			return nil
+		}
+
+		if voted {
+			ShowStatus(g, fmt.Sprintf("Removed your vote on %s", issueKey))
+		} else {
+			ShowStatus(g, fmt.Sprintf("Voted for %s", issueKey))
+		}
+
+		if CurrentIssue != nil && CurrentIssue.Key == issueKey {
+			return OpenIssue(g, issueKey, false)
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+// refreshAfterWatch reloads the "watching" list and the Details view when it
+// displays the same issue
+func refreshAfterWatch(g *ui.Gui, issueKey string) error {
+	if strings.EqualFold(IssuesList.code, WatchingKey) {
+		if err := FetchIssues(g, IssuesList.code); err != nil {
+			IssuesList.SetTitle(" Issues (Error!) ")
+			return nil
+		}
+		IssuesList.SetTitle(makeIssuesTitle())
+	}
+
+	if CurrentIssue != nil && CurrentIssue.Key == issueKey {
+		return OpenIssue(g, issueKey, false)
+	}
+
+	return nil
+}
+
+// getActionIssueKey returns the issue displayed in the Details view, or the
+// one selected in the Issues view
+func getActionIssueKey(v *ui.View) string {
+	if v.Name() == DetailsView {
+		if CurrentIssue == nil {
+			return ""
+		}
+		return CurrentIssue.Key
+	}
+
+	return issueKeyFromRow(IssuesList.CurrentItem())
+}
+
+// getActionIssue returns the issue the action applies to, like
+// getActionIssueKey
+func getActionIssue(v *ui.View) *jira.Issue {
+	if v.Name() == DetailsView {
+		return CurrentIssue
+	}
+
+	return findListedIssue(issueKeyFromRow(IssuesList.CurrentItem()))
+}
+
+func isWatching(issue *jira.Issue) bool {
+	return issue.Fields.Watches != nil && issue.Fields.Watches.IsWatching
+}
+
+func getWatchCount(issue *jira.Issue) int {
+	if issue.Fields.Watches == nil {
+		return 0
+	}
+
+	return issue.Fields.Watches.WatchCount
+}
+
+// getVotes reads the "votes" field, it is not part of the fields known by
+// the client
+func getVotes(issue *jira.Issue) (int, bool) {
+	value, ok := issue.Fields.Unknowns.Value("votes")
+	if !ok {
+		return 0, false
+	}
+
+	votes, ok := value.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+
+	count, _ := votes["votes"].(float64)
+	voted, _ := votes["hasVoted"].(bool)
+
+	return int(count), voted
+}
+
+// formatWatchers is displayed in the Overview tab, e.g. "3 (watching)"
+func formatWatchers(issue *jira.Issue) string {
+	if isWatching(issue) {
+		return fmt.Sprintf("%d (watching)", getWatchCount(issue))
+	}
+
+	return fmt.Sprint(getWatchCount(issue))
+}
+
+func formatVotes(issue *jira.Issue) string {
+	count, voted := getVotes(issue)
+	if voted {
+		return fmt.Sprintf("%d (voted)", count)
+	}
+
+	return fmt.Sprint(count)
+}
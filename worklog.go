@@ -0,0 +1,364 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+)
+
+// The running timer is saved in this file of the state directory
+const TimerStateFile = "timer.json"
+
+// Durations as written in Jira, e.g. "1h 30m" or "2d"
+var worklogDurationRegexp = regexp.MustCompile(`^(\d+(\.\d+)?[wdhm]\s*)+$`)
+
+// WorklogTimer measures the time spent on an issue, it survives restarts
+type WorklogTimer struct {
+	IssueKey string    `json:"issueKey"`
+	Started  time.Time `json:"started"`
+}
+
+func (t *WorklogTimer) Elapsed() time.Duration {
+	return time.Since(t.Started)
+}
+
+var (
+	RunningTimer *WorklogTimer
+
+	// The worklogs displayed in the Worklog tab of the Details view
+	currentWorklogs []jira.WorklogRecord
+
+	// Whether the Log work prompt was opened from the Details view
+	logWorkFromDetails bool
+)
+
+// loadTimer restores the timer left running by the previous session
+func loadTimer() {
+	data, err := os.ReadFile(getStatePath(TimerStateFile))
+	if err != nil {
+		return
+	}
+
+	timer := &WorklogTimer{}
+	if err := json.Unmarshal(data, timer); err != nil || timer.IssueKey == "" {
+		return
+	}
+
+	RunningTimer = timer
+}
+
+// saveTimer writes the running timer, or removes the file when it is stopped
+func saveTimer() error {
+	path := getStatePath(TimerStateFile)
+
+	if RunningTimer == nil {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(RunningTimer)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Start the timer on the selected issue, or stop the running timer and ask
+// for the worklog to post
+func ToggleTimer(g *ui.Gui, v *ui.View) error {
+	if RunningTimer != nil {
+		return stopTimer(g, v)
+	}
+
+	key := issueKeyFromRow(IssuesList.CurrentItem())
+	if v.Name() == DetailsView && CurrentIssue != nil {
+		key = CurrentIssue.Key
+	}
+	if key == "" {
+		return nil
+	}
+
+	RunningTimer = &WorklogTimer{IssueKey: key, Started: time.Now()}
+	if err := saveTimer(); err != nil {
+		ShowStatus(g, fmt.Sprintf("Failed to save the timer: %s", err))
+		return nil
+	}
+
+	ShowStatus(g, fmt.Sprintf("Timer started on %s", key))
+
+	return nil
+}
+
+// stopTimer opens the Log work prompt prefilled with the elapsed time, the
+// timer keeps running until the worklog is posted
+func stopTimer(g *ui.Gui, v *ui.View) error {
+	logWorkFromDetails = v.Name() == DetailsView
+
+	IssuesList.Unfocus()
+	DetailsList.Unfocus()
+
+	content := formatWorklogInput(formatJiraDuration(RunningTimer.Elapsed()), "")
+	createPromptView(g, CreateDialogOptions{
+		title:   fmt.Sprintf("%s| %s ", LogWorkTitle, RunningTimer.IssueKey),
+		content: content,
+		value:   RunningTimer.IssueKey,
+	})
+
+	return PromptDialog.SetCursor(len(content), 0)
+}
+
+// submitWorklog posts the worklog typed in the Log work prompt, errors are
+// displayed below the prompt so the input is not lost
+func submitWorklog(g *ui.Gui, input string) error {
+	timeSpent, comment, err := parseWorklogInput(input)
+	if err != nil {
+		PromptDialog.Subtitle = fmt.Sprintf(" %s ", err)
+		return nil
+	}
+
+	issueKey := PromptDialog.value
+
+	started := time.Now()
+	if RunningTimer != nil && RunningTimer.IssueKey == issueKey {
+		started = RunningTimer.Started
+	}
+	startedAt := jira.Time(started)
+
+	err = AddWorklog(issueKey, &jira.WorklogRecord{
+		TimeSpent: timeSpent,
+		Comment:   comment,
+		Started:   &startedAt,
+	})
+	if err != nil {
+		PromptDialog.Subtitle = fmt.Sprintf(" %s ", getFieldError(err, "timeLogged"))
+		return nil
+	}
+
+	if RunningTimer != nil && RunningTimer.IssueKey == issueKey {
+		RunningTimer = nil
+		if err := saveTimer(); err != nil {
+			ShowStatus(g, fmt.Sprintf("Failed to save the timer: %s", err))
+		}
+	}
+
+	deletePromptView(g)
+	focusAfterLogWork(g)
+	ShowStatus(g, fmt.Sprintf("Logged %s on %s", timeSpent, issueKey))
+
+	if CurrentIssue != nil && CurrentIssue.Key == issueKey {
+		return OpenIssue(g, issueKey, false)
+	}
+
+	return nil
+}
+
+func focusAfterLogWork(g *ui.Gui) {
+	if logWorkFromDetails {
+		DetailsList.Focus(g)
+	} else {
+		IssuesList.Focus(g)
+	}
+}
+
+// Edit the worklog on the current line, only the worklogs of the current
+// user can be changed
+func EditWorklog(g *ui.Gui, v *ui.View) error {
+	record := findOwnWorklog(g)
+	if record == nil {
+		return nil
+	}
+
+	DetailsList.Unfocus()
+
+	content := formatWorklogInput(record.TimeSpent, record.Comment)
+	createPromptView(g, CreateDialogOptions{
+		title:   fmt.Sprintf("%s| %s ", EditWorklogTitle, CurrentIssue.Key),
+		content: content,
+		value:   record.ID,
+	})
+
+	return PromptDialog.SetCursor(len(content), 0)
+}
+
+func submitEditedWorklog(g *ui.Gui, input string) error {
+	timeSpent, comment, err := parseWorklogInput(input)
+	if err != nil {
+		PromptDialog.Subtitle = fmt.Sprintf(" %s ", err)
+		return nil
+	}
+
+	err = UpdateWorklog(CurrentIssue.Key, PromptDialog.value, &jira.WorklogRecord{
+		TimeSpent: timeSpent,
+		Comment:   comment,
+	})
+	if err != nil {
+		PromptDialog.Subtitle = fmt.Sprintf(" %s ", getFieldError(err, "timeLogged"))
+		return nil
+	}
+
+	deletePromptView(g)
+	DetailsList.Focus(g)
+	ShowStatus(g, "Worklog updated")
+
+	return OpenIssue(g, CurrentIssue.Key, false)
+}
+
+// Ask for confirmation before deleting the worklog on the current line
+func RemoveWorklog(g *ui.Gui, v *ui.View) error {
+	record := findOwnWorklog(g)
+	if record == nil {
+		return nil
+	}
+
+	DetailsList.Unfocus()
+
+	createAlertView(g, CreateDialogOptions{
+		title: DeleteWorklogTitle,
+		content: fmt.Sprintf(`
+			The worklog of %s on %s will be deleted.
+			Do you want to proceed?`, record.TimeSpent, formatWorklogStarted(record)),
+		value: record.ID,
+	})
+
+	return nil
+}
+
+// findOwnWorklog returns the worklog on the current line of the Details view
+// if it belongs to the current user
+func findOwnWorklog(g *ui.Gui) *jira.WorklogRecord {
+	ref := currentDetailsRef()
+	if CurrentIssue == nil || ref.Kind != WorklogRef {
+		return nil
+	}
+
+	var record *jira.WorklogRecord
+	for index := range currentWorklogs {
+		if currentWorklogs[index].ID == ref.ID {
+			record = &currentWorklogs[index]
+			break
+		}
+	}
+	if record == nil {
+		return nil
+	}
+
+	me, err := GetCurrentUser()
+	if err != nil {
+		ShowStatus(g, err.Error())
+		return nil
+	}
+
+	if record.Author == nil || record.Author.AccountID != me.AccountID {
+		ShowStatus(g, "Only your own worklogs can be changed")
+		return nil
+	}
+
+	return record
+}
+
+// makeWorklogLines is used by the Worklog tab of the Details view
+func makeWorklogLines(g *ui.Gui, issue *jira.Issue) *DetailsLines {
+	lines := &DetailsLines{}
+	currentWorklogs = nil
+
+	value, loaded, err := getTabData(g, WorklogTab, issue.Key, func() (interface{}, error) {
+		return GetWorklogs(issue.Key)
+	})
+	if !loaded {
+		lines.Add(DetailsRef{}, "Loading worklogs...")
+		return lines
+	}
+	if err != nil {
+		lines.Add(DetailsRef{}, fmt.Sprintf("Failed to load worklogs: %s", err))
+		return lines
+	}
+
+	records := value.([]jira.WorklogRecord)
+	currentWorklogs = records
+
+	if len(records) == 0 {
+		lines.Add(DetailsRef{}, "No work logged")
+		return lines
+	}
+
+	total := 0
+	for _, record := range records {
+		total += record.TimeSpentSeconds
+	}
+
+	lines.Add(DetailsRef{}, fmt.Sprintf("Total logged: %s", formatJiraDuration(time.Duration(total)*time.Second)), "")
+
+	width := DetailsList.width()
+	for _, record := range records {
+		ref := DetailsRef{WorklogRef, record.ID}
+		lines.Add(ref, fmt.Sprintf("%s  %-8s %s", formatWorklogStarted(&record), record.TimeSpent, getUserName(record.Author)))
+		for _, paragraph := range strings.Split(strings.TrimSpace(record.Comment), "\n") {
+			if paragraph == "" {
+				continue
+			}
+			for _, line := range wrapText(paragraph, width-4) {
+				lines.Add(ref, fmt.Sprintf("    %s", line))
+			}
+		}
+	}
+
+	return lines
+}
+
+// parseWorklogInput reads "<time spent> | <comment>", e.g. "1h 30m | Review"
+func parseWorklogInput(input string) (string, string, error) {
+	timeSpent, comment, _ := strings.Cut(input, "|")
+	timeSpent = strings.TrimSpace(timeSpent)
+
+	if !worklogDurationRegexp.MatchString(timeSpent) {
+		return "", "", fmt.Errorf("expected a time like 1h 30m before the |")
+	}
+
+	return timeSpent, strings.TrimSpace(comment), nil
+}
+
+func formatWorklogInput(timeSpent string, comment string) string {
+	return fmt.Sprintf("%s | %s", timeSpent, comment)
+}
+
+// formatJiraDuration writes the duration the way Jira does, e.g. "1h 5m",
+// rounded to the minute and never less than a minute
+func formatJiraDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	hours, minutes := minutes/60, minutes%60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+func formatWorklogStarted(record *jira.WorklogRecord) string {
+	if record.Started == nil {
+		return ""
+	}
+
+	return time.Time(*record.Started).Local().Format(DateTimeLayout)
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	jira "github.com/andygrunwald/go-jira/v2/cloud"
+	ui "github.com/awesome-gocui/gocui"
+	plumbing "github.com/go-git/go-git/v5/plumbing"
+	config "github.com/gookit/config/v2"
+)
+
+// Used when neither git.worktreeDir nor the project define the directory of
+// the worktrees
+const DefaultWorktreeDir = "~/work/{{.Repo}}-{{.Key}}"
+
+// Worktree is a linked worktree of a repository, as listed by
+// git worktree list --porcelain
+type Worktree struct {
+	Path   string
+	Branch plumbing.ReferenceName
+	Head   string
+}
+
+// WorktreeDirData is what the worktree directory templates can use, e.g.
+// ~/work/{{.Repo}}-{{.Key}}
+type WorktreeDirData struct {
+	Repo    string
+	Key     string
+	Project string
+}
+
+var (
+	// The worktrees offered for removal by the Worktrees picker, in the order
+	// of its items. The last item creates a worktree when worktreeIssue is set
+	worktreeChoices []Worktree
+	worktreeIssue   *jira.Issue
+
+	// The list focused before the Worktrees picker was opened
+	worktreeReturnList *List
+)
+
+// Create, or remove, the worktree of the selected issue
+func WorktreePrompt(g *ui.Gui, v *ui.View) error {
+	issue := getActionIssue(v)
+	if issue == nil {
+		return nil
+	}
+
+	projectCode, _, _ := strings.Cut(issue.Key, "-")
+
+	return selectRepository(g, v, projectCode, func(g *ui.Gui) error {
+		return worktreePicker(g, v, issue)
+	})
+}
+
+// worktreePicker lists the worktrees of the issue to remove them, followed
+// by the creation of a new one. The done issues are not offered a new one
+func worktreePicker(g *ui.Gui, v *ui.View, issue *jira.Issue) error {
+	worktrees, err := findIssueWorktrees(gitRepoPath, issue.Key)
+	if err != nil {
+		ShowStatus(g, fmt.Sprintf("Failed to list the worktrees: %s", err))
+		return nil
+	}
+
+	dir, err := makeWorktreeDir(gitRepoPath, issue)
+	if err != nil {
+		ShowStatus(g, err.Error())
+		return nil
+	}
+
+	worktreeChoices = worktrees
+	worktreeIssue = nil
+
+	items := make([]string, 0, len(worktrees)+1)
+	for _, worktree := range worktrees {
+		items = append(items, fmt.Sprintf("Remove %s (%s)", worktree.Path, worktree.describe()))
+	}
+
+	if !isIssueDone(*issue) && !containsWorktree(worktrees, dir) {
+		worktreeIssue = issue
+		items = append(items, fmt.Sprintf("Create %s", dir))
+	}
+
+	if len(items) == 0 {
+		ShowStatus(g, fmt.Sprintf("%s is done and has no worktree", issue.Key))
+		return nil
+	}
+
+	worktreeReturnList = IssuesList
+	if v.Name() == DetailsView {
+		worktreeReturnList = DetailsList
+	}
+	worktreeReturnList.Unfocus()
+
+	createPickerView(g, CreateDialogOptions{title: fmt.Sprintf("%s| %s ", WorktreeTitle, issue.Key)}, items)
+
+	return nil
+}
+
+// worktreeChoice is called with the index of the item chosen in the
+// Worktrees picker
+func worktreeChoice(g *ui.Gui, index int) error {
+	cancelWorktreePicker(g)
+
+	if index < len(worktreeChoices) {
+		worktree := worktreeChoices[index]
+		return runWorktreeAction(g, fmt.Sprintf("Removing %s...", worktree.Path), func() (string, error) {
+			return fmt.Sprintf("Removed %s", worktree.Path), removeWorktree(gitRepoPath, worktree)
+		})
+	}
+
+	if worktreeIssue == nil {
+		return nil
+	}
+
+	issue := worktreeIssue
+	dir, err := makeWorktreeDir(gitRepoPath, issue)
+	if err != nil {
+		ShowStatus(g, err.Error())
+		return nil
+	}
+
+	return runWorktreeAction(g, fmt.Sprintf("Creating %s...", dir), func() (string, error) {
+		branch, err := createWorktree(gitRepoPath, dir, issue)
+		return fmt.Sprintf("Created %s on %s", dir, branch), err
+	})
+}
+
+func cancelWorktreePicker(g *ui.Gui) {
+	if worktreeReturnList != nil {
+		worktreeReturnList.Focus(g)
+	}
+}
+
+// runWorktreeAction runs the git commands in the background, the Git tab and
+// the branch markers are refreshed once they are done
+func runWorktreeAction(g *ui.Gui, progress string, action func() (string, error)) error {
+	ShowStatus(g, progress)
+
+	go func() {
+		message, err := action()
+
+		g.Update(func(g *ui.Gui) error {
+			if err != nil {
+				ShowStatus(g, fmt.Sprintf("Failed: %s", err))
+				return nil
+			}
+
+			ShowStatus(g, message)
+			refreshLocalBranchKeys()
+
+			if CurrentIssue != nil {
+				forgetTabsData(CurrentIssue.Key)
+				if CurrentTab == GitTab {
+					renderDetails(g)
+				}
+			}
+
+			return redrawIssueRows()
+		})
+	}()
+
+	return nil
+}
+
+// createWorktree checks out the branch of the issue in the directory. The
+// first local branch of the issue is used, then its first remote branch,
+// otherwise a branch named from the branch template starts from the default
+// branch
+func createWorktree(repoPath string, dir string, issue *jira.Issue) (string, error) {
+	repo, err := openRepositoryAt(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	local, remote, err := findIssueBranches(repo, issue.Key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return "", err
+	}
+
+	switch {
+	case len(local) > 0:
+		branch := local[0].Short()
+		return branch, runGit(repoPath, "worktree", "add", dir, branch)
+	case len(remote) > 0:
+		_, branch, _ := strings.Cut(remote[0].Short(), "/")
+		return branch, runGit(repoPath, "worktree", "add", "--track", "-b", branch, dir, remote[0].Short())
+	}
+
+	branch, err := makeBranchName(issue)
+	if err != nil {
+		return "", err
+	}
+
+	base := getDefaultBranch(repo)
+
+	return branch, runGit(repoPath, "worktree", "add", "-b", branch, dir, base.Short())
+}
+
+// removeWorktree refuses to remove a worktree with changes, like git does.
+// The branch is kept
+func removeWorktree(repoPath string, worktree Worktree) error {
+	return runGit(repoPath, "worktree", "remove", worktree.Path)
+}
+
+// listWorktrees lists the linked worktrees of the repository, the main one
+// is left out since it can not be removed
+func listWorktrees(repoPath string) ([]Worktree, error) {
+	output, err := runGitOutput(repoPath, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees := make([]Worktree, 0)
+	for index, block := range strings.Split(strings.TrimSpace(output), "\n\n") {
+		if index == 0 {
+			continue
+		}
+
+		worktree := Worktree{}
+		for _, line := range strings.Split(block, "\n") {
+			name, value, _ := strings.Cut(line, " ")
+			switch name {
+			case "worktree":
+				worktree.Path = value
+			case "HEAD":
+				worktree.Head = value
+			case "branch":
+				worktree.Branch = plumbing.ReferenceName(value)
+			}
+		}
+
+		if worktree.Path != "" {
+			worktrees = append(worktrees, worktree)
+		}
+	}
+
+	return worktrees, nil
+}
+
+// findIssueWorktrees returns the worktrees whose branch or directory
+// contains the key
+func findIssueWorktrees(repoPath string, key string) ([]Worktree, error) {
+	worktrees, err := listWorktrees(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := newIssueKeyMatcher(key)
+	found := make([]Worktree, 0)
+	for _, worktree := range worktrees {
+		if matcher.MatchString(worktree.Branch.Short()) || matcher.MatchString(filepath.Base(worktree.Path)) {
+			found = append(found, worktree)
+		}
+	}
+
+	return found, nil
+}
+
+func addWorktreeLines(lines *DetailsLines, worktrees []Worktree, err error) {
+	if err != nil {
+		lines.Add(DetailsRef{}, fmt.Sprintf("  Failed to list the worktrees: %s", err))
+		return
+	}
+
+	if len(worktrees) == 0 {
+		lines.Add(DetailsRef{}, "  (none)")
+		return
+	}
+
+	for _, worktree := range worktrees {
+		lines.Add(DetailsRef{}, fmt.Sprintf("  %s (%s)", worktree.Path, worktree.describe()))
+	}
+}
+
+// makeWorktreeDir returns the directory of the worktree of the issue, from
+// projects.<code>.worktreeDir or git.worktreeDir. A relative directory is
+// relative to the repository
+func makeWorktreeDir(repoPath string, issue *jira.Issue) (string, error) {
+	projectCode, _, _ := strings.Cut(issue.Key, "-")
+
+	text := config.String(fmt.Sprintf("%s.%s.worktreeDir", ProjectsKey, strings.ToLower(projectCode)))
+	if text == "" {
+		text = config.String(WorktreeDirKey, DefaultWorktreeDir)
+	}
+
+	tmpl, err := template.New("worktree").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid worktree directory: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	data := WorktreeDirData{Repo: filepath.Base(repoPath), Key: issue.Key, Project: projectCode}
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("invalid worktree directory: %w", err)
+	}
+
+	dir := expandHome(strings.TrimSpace(buffer.String()))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoPath, dir)
+	}
+
+	return filepath.Clean(dir), nil
+}
+
+func containsWorktree(worktrees []Worktree, dir string) bool {
+	for _, worktree := range worktrees {
+		if filepath.Clean(worktree.Path) == dir {
+			return true
+		}
+	}
+
+	return false
+}
+
+// describe names the branch of the worktree, or its commit when detached
+func (w Worktree) describe() string {
+	if w.Branch != "" {
+		return w.Branch.Short()
+	}
+	if len(w.Head) >= 7 {
+		return fmt.Sprintf("detached at %s", w.Head[:7])
+	}
+
+	return "detached"
+}